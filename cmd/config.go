@@ -9,8 +9,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/ratler/airuler/internal/compiler"
 	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/template"
 	"github.com/spf13/cobra"
 	yaml "gopkg.in/yaml.v3"
 )
@@ -64,6 +69,26 @@ var configSetTemplateDirCmd = &cobra.Command{
 	},
 }
 
+var configExplainCmd = &cobra.Command{
+	Use:   "explain <template> <target>",
+	Short: "Show the effective template context and where each value came from",
+	Long: `Show the data a template is compiled with for a target, and the layer that set
+each value: the template's front matter, the project's vendor_overrides, the
+vendor's airuler.yaml, or a built-in default.
+
+Examples:
+  airuler config explain coding-style claude
+  airuler config explain rules/python cursor`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		target := compiler.Target(args[1])
+		if !isValidTarget(target) {
+			return fmt.Errorf("invalid target: %s", args[1])
+		}
+		return explainTemplate(strings.TrimSuffix(args[0], ".tmpl"), target)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 
@@ -71,6 +96,7 @@ func init() {
 	configCmd.AddCommand(configPathCmd)
 	configCmd.AddCommand(configEditCmd)
 	configCmd.AddCommand(configSetTemplateDirCmd)
+	configCmd.AddCommand(configExplainCmd)
 }
 
 func initGlobalConfig() error {
@@ -151,6 +177,12 @@ func editGlobalConfig() error {
 		return nil
 	}
 
+	// The editor waits for the user; graphical editors work without a terminal, so only
+	// --no-input prevents it
+	if noInput {
+		return fmt.Errorf("%w: config edit opens an editor (--no-input is set)", errConfirmationNeeded)
+	}
+
 	// Get editor preference in order of precedence
 	editor := getEditor()
 	if editor == "" {
@@ -228,3 +260,170 @@ func setTemplateDir(path string) error {
 	fmt.Printf("✅ Template directory set to: %s\n", cleanPath)
 	return nil
 }
+
+// explainedValue is a template data field with the configuration layer that set it
+type explainedValue struct {
+	field  string
+	value  interface{}
+	origin string
+}
+
+// explainTemplate prints the context a template compiles with for a target
+func explainTemplate(templateName string, target compiler.Target) error {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	projectConfig, err := loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	vendorConfigs, err := config.LoadVendorConfigs(currentDir, projectConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load vendor configurations: %w", err)
+	}
+
+	templateDirs := append([]string{"templates"}, getVendorTemplateDirs()...)
+	templates, _, err := loadTemplatesFromDirsWithOutput(templateDirs, false)
+	if err != nil {
+		return err
+	}
+	templateSource, exists := templates[templateName]
+	if !exists {
+		return fmt.Errorf("template %s not found in %s", templateName, strings.Join(templateDirs, ", "))
+	}
+
+	frontMatter, err := parseTemplateFrontMatter(templateSource.Content)
+	if err != nil {
+		return fmt.Errorf("failed to parse front matter of %s: %w", templateName, err)
+	}
+	if frontMatter.Custom == nil {
+		frontMatter.Custom = make(map[string]interface{})
+	}
+	context := vendorConfigs.ResolveTemplateContext(templateSource.SourceType, string(target))
+	data := createTemplateData(templateName, *frontMatter, context, string(target))
+
+	fmt.Printf("🔎 %s for %s\n", templateName, target)
+	fmt.Printf("   %-12s %s (%s)\n\n", "Source:", templateSource.SourceType, templateSource.SourcePath)
+
+	values := explainTemplateData(data, *frontMatter, context, projectConfig.VendorOverrides[templateSource.SourceType])
+	fmt.Printf("   %-16s %-30s %s\n", "FIELD", "VALUE", "FROM")
+	for _, value := range values {
+		fmt.Printf("   %-16s %-30s %s\n", value.field, formatExplainedValue(value.value), value.origin)
+	}
+	return nil
+}
+
+// explainTemplateData lists the fields of compiled template data with the layer that set each,
+// following the precedence of createTemplateData: front matter, then vendor variables and
+// template defaults, where project vendor_overrides take precedence over the vendor's airuler.yaml
+func explainTemplateData(
+	data template.Data,
+	frontMatter TemplateFrontMatter,
+	context config.ResolvedTemplateContext,
+	override config.VendorConfig,
+) []explainedValue {
+	vendorName := context.SourceType
+
+	// vendorOrigin names the file that set a key in a section of the merged vendor config
+	vendorOrigin := func(section, key string, overridden bool) string {
+		if overridden {
+			return fmt.Sprintf("airuler.yaml vendor_overrides.%s.%s.%s", vendorName, section, key)
+		}
+		return fmt.Sprintf("%s/airuler.yaml %s.%s", vendorName, section, key)
+	}
+	defaultOrigin := func(key string) (string, bool) {
+		if _, exists := context.TemplateDefaults[key]; !exists {
+			return "", false
+		}
+		_, overridden := override.TemplateDefaults[key]
+		return vendorOrigin("template_defaults", key, overridden), true
+	}
+
+	values := []explainedValue{
+		{"Name", data.Name, "template path"},
+		{"Target", data.Target, "command line"},
+	}
+
+	descriptionOrigin := "default"
+	if frontMatter.Description != "" {
+		descriptionOrigin = "front matter description"
+	} else if origin, ok := defaultOrigin("description"); ok {
+		descriptionOrigin = origin
+	}
+	values = append(values, explainedValue{"Description", data.Description, descriptionOrigin})
+
+	globsOrigin := "default"
+	if frontMatter.Globs != nil {
+		globsOrigin = "front matter globs"
+	}
+	values = append(values, explainedValue{"Globs", data.Globs, globsOrigin})
+
+	switch {
+	case frontMatter.ClaudeMode != "":
+		values = append(values, explainedValue{"Mode", data.Mode, "front matter claude_mode"})
+	case context.TargetConfig.DefaultMode != "":
+		overridden := override.Targets[data.Target].DefaultMode != ""
+		values = append(values, explainedValue{"Mode", data.Mode, vendorOrigin("targets", data.Target+".default_mode", overridden)})
+	case data.Mode != "":
+		overridden := override.Compilation.DefaultMode != ""
+		values = append(values, explainedValue{"Mode", data.Mode, vendorOrigin("compilation", "default_mode", overridden)})
+	}
+
+	fields := []struct {
+		field, key  string
+		value       interface{}
+		frontMatter bool
+	}{
+		{"ProjectType", "project_type", data.ProjectType, frontMatter.ProjectType != ""},
+		{"Language", "language", data.Language, frontMatter.Language != ""},
+		{"Framework", "framework", data.Framework, frontMatter.Framework != ""},
+		{"Tags", "tags", data.Tags, frontMatter.Tags != nil},
+		{"AlwaysApply", "always_apply", data.AlwaysApply, frontMatter.AlwaysApply != ""},
+		{"Documentation", "documentation", data.Documentation, frontMatter.Documentation != ""},
+		{"StyleGuide", "style_guide", data.StyleGuide, frontMatter.StyleGuide != ""},
+		{"Examples", "examples", data.Examples, frontMatter.Examples != ""},
+	}
+	for _, f := range fields {
+		origin, fromDefaults := defaultOrigin(f.key)
+		switch {
+		case f.frontMatter:
+			values = append(values, explainedValue{f.field, f.value, "front matter " + f.key})
+		case fromDefaults:
+			values = append(values, explainedValue{f.field, f.value, origin})
+		}
+	}
+
+	customKeys := make([]string, 0, len(data.Custom))
+	for key := range data.Custom {
+		customKeys = append(customKeys, key)
+	}
+	sort.Strings(customKeys)
+	for _, key := range customKeys {
+		origin := ""
+		if _, exists := frontMatter.Custom[key]; exists {
+			origin = "front matter custom." + key
+		} else if _, exists := context.Variables[key]; exists {
+			_, overridden := override.Variables[key]
+			origin = vendorOrigin("variables", key, overridden)
+		} else {
+			origin, _ = defaultOrigin("custom")
+			origin += "." + key
+		}
+		values = append(values, explainedValue{"Custom." + key, data.Custom[key], origin})
+	}
+
+	return values
+}
+
+// formatExplainedValue prints a value on one line, shortening long text
+func formatExplainedValue(value interface{}) string {
+	text := strings.ReplaceAll(fmt.Sprintf("%v", value), "\n", " ")
+	if utf8.RuneCountInString(text) > 30 {
+		text = string([]rune(text)[:29]) + "…"
+	}
+	if text == "" {
+		return "-"
+	}
+	return text
+}
@@ -280,3 +280,44 @@ func TestConfigIntegration(t *testing.T) {
 		t.Error("initGlobalConfig() should fail when config already exists")
 	}
 }
+
+func TestExplainTemplateData(t *testing.T) {
+	frontMatter := TemplateFrontMatter{
+		Description: "Go style",
+		Custom:      map[string]interface{}{"owner": "me"},
+	}
+	context := config.ResolvedTemplateContext{
+		SourceType:       "go-rules",
+		TemplateDefaults: map[string]interface{}{"language": "go", "framework": "gin"},
+		Variables:        map[string]interface{}{"team": "platform", "owner": "vendor"},
+		TargetConfig:     config.TargetConfig{DefaultMode: "memory"},
+	}
+	override := config.VendorConfig{
+		TemplateDefaults: map[string]interface{}{"framework": "gin"},
+		Targets:          map[string]config.TargetConfig{"claude": {DefaultMode: "memory"}},
+	}
+	data := createTemplateData("style", frontMatter, context, "claude")
+
+	origins := make(map[string]string)
+	for _, value := range explainTemplateData(data, frontMatter, context, override) {
+		origins[value.field] = value.origin
+	}
+
+	want := map[string]string{
+		"Description":  "front matter description",
+		"Globs":        "default",
+		"Mode":         "airuler.yaml vendor_overrides.go-rules.targets.claude.default_mode",
+		"Language":     "go-rules/airuler.yaml template_defaults.language",
+		"Framework":    "airuler.yaml vendor_overrides.go-rules.template_defaults.framework",
+		"Custom.owner": "front matter custom.owner",
+		"Custom.team":  "go-rules/airuler.yaml variables.team",
+	}
+	for field, origin := range want {
+		if origins[field] != origin {
+			t.Errorf("origin of %s = %q, want %q", field, origins[field], origin)
+		}
+	}
+	if _, exists := origins["ProjectType"]; exists {
+		t.Error("unset fields should not be explained")
+	}
+}
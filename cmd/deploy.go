@@ -13,12 +13,13 @@ import (
 )
 
 var (
-	deployNoCompile   bool
-	deployProject     string
-	deployTargets     string
-	deployInteractive bool
-	deployForce       bool
-	deployDryRun      bool
+	deployNoCompile      bool
+	deployProject        string
+	deployTargets        string
+	deployInteractive    bool
+	deployForce          bool
+	deployDryRun         bool
+	deployTrackInProject bool
 )
 
 var deployCmd = &cobra.Command{
@@ -39,11 +40,13 @@ Examples:
   airuler deploy cursor                  # Deploy only for Cursor target globally
   airuler deploy cursor my-rule          # Deploy specific rule for Cursor globally
   airuler deploy --project ./my-app      # Deploy to specific project directory
+  airuler deploy --project . --track-in-project  # Record installs in ./.airuler/installs.yaml
   airuler deploy --no-compile            # Install existing compiled rules only
   airuler deploy --interactive           # Interactive template selection
   airuler deploy --targets cursor,claude # Deploy only to specific targets
   airuler deploy --dry-run               # Show what would be deployed`,
-	Args: cobra.MaximumNArgs(2),
+	Args:        cobra.MaximumNArgs(2),
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
 	RunE: func(_ *cobra.Command, args []string) error {
 		var targetFilter, ruleFilter string
 		if len(args) >= 1 {
@@ -53,7 +56,7 @@ Examples:
 			ruleFilter = args[1]
 		}
 
-		return runDeploy(targetFilter, ruleFilter)
+		return runWithResult("deploy", func() error { return runDeploy(targetFilter, ruleFilter) })
 	},
 }
 
@@ -66,9 +69,13 @@ func init() {
 	deployCmd.Flags().BoolVarP(&deployInteractive, "interactive", "i", false, "interactive template selection")
 	deployCmd.Flags().BoolVarP(&deployForce, "force", "f", false, "overwrite existing files without confirmation")
 	deployCmd.Flags().BoolVarP(&deployDryRun, "dry-run", "n", false, "show what would be deployed without executing")
+	deployCmd.Flags().BoolVar(&deployTrackInProject, "track-in-project", false, "record project installations in <project>/.airuler/installs.yaml")
 }
 
 func runDeploy(targetFilter, ruleFilter string) error {
+	if deployInteractive && jsonOutput() {
+		return fmt.Errorf("--interactive cannot be combined with --output json")
+	}
 	if deployDryRun {
 		return runDeployDryRun(targetFilter, ruleFilter)
 	}
@@ -103,13 +110,13 @@ func runDeploy(targetFilter, ruleFilter string) error {
 
 	// Step 1: Compile templates (if not skipped)
 	if !deployNoCompile {
-		if err := runDeployCompile(targetFilter); err != nil {
+		if err := runStep("compile", func() error { return runDeployCompile(targetFilter) }); err != nil {
 			return fmt.Errorf("compilation failed: %w", err)
 		}
 	}
 
 	// Step 2: Install templates
-	if err := runDeployInstall(targetFilter, ruleFilter); err != nil {
+	if err := runStep("install", func() error { return runDeployInstall(targetFilter, ruleFilter) }); err != nil {
 		return fmt.Errorf("installation failed: %w", err)
 	}
 
@@ -156,7 +163,7 @@ func runDeployDryRun(targetFilter, ruleFilter string) error {
 
 	// Show what templates would be compiled/installed
 	if err := showDeployTargets(targetFilter, ruleFilter); err != nil {
-		fmt.Printf("Warning: could not determine deployment targets: %v\n", err)
+		return fmt.Errorf("failed to determine deployment targets: %w", err)
 	}
 
 	fmt.Println("\n💡 Run without --dry-run to execute these changes")
@@ -214,12 +221,14 @@ func runDeployInstall(targetFilter, ruleFilter string) error {
 	originalInstallProject := installProject
 	originalInstallForce := installForce
 	originalInstallInteractive := installInteractive
+	originalInstallTrackInProject := installTrackInProject
 
 	// Configure install command
 	installTarget = targetFilter
 	installRule = ruleFilter
 	installGlobal = deployProject == ""
 	installProject = deployProject
+	installTrackInProject = deployTrackInProject && deployProject != ""
 	installForce = deployForce
 	installInteractive = false
 
@@ -231,6 +240,7 @@ func runDeployInstall(targetFilter, ruleFilter string) error {
 		installProject = originalInstallProject
 		installForce = originalInstallForce
 		installInteractive = originalInstallInteractive
+		installTrackInProject = originalInstallTrackInProject
 	}()
 
 	// Run installation
@@ -250,12 +260,14 @@ func runDeployInteractive(targetFilter, ruleFilter string) error {
 	originalInstallProject := installProject
 	originalInstallForce := installForce
 	originalInstallInteractive := installInteractive
+	originalInstallTrackInProject := installTrackInProject
 
 	// Configure install command for interactive mode
 	installTarget = targetFilter
 	installRule = ruleFilter
 	installGlobal = deployProject == ""
 	installProject = deployProject
+	installTrackInProject = deployTrackInProject && deployProject != ""
 	installForce = deployForce
 	installInteractive = true
 
@@ -267,6 +279,7 @@ func runDeployInteractive(targetFilter, ruleFilter string) error {
 		installProject = originalInstallProject
 		installForce = originalInstallForce
 		installInteractive = originalInstallInteractive
+		installTrackInProject = originalInstallTrackInProject
 	}()
 
 	// Run interactive installation
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+func TestRunDeployDryRunCollision(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	files := map[string]string{
+		"airuler.yaml":                       "defaults:\n  include_vendors: [\"*\"]\n  collisions: error\n",
+		"vendors/acme/templates/style.tmpl":  "acme",
+		"vendors/beta/templates/style.tmpl":  "beta",
+		"vendors/beta/templates/review.tmpl": "review",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	lockFile := config.NewLockFile()
+	lockFile.Vendors["acme"] = config.VendorLock{}
+	lockFile.Vendors["beta"] = config.VendorLock{}
+	if err := config.SaveLockFile(config.LockFileName, lockFile); err != nil {
+		t.Fatalf("Failed to save lock file: %v", err)
+	}
+	viper.SetConfigFile("airuler.yaml")
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	var err error
+	captureOutput(func() { err = runDeployDryRun("", "") })
+	if err == nil || !strings.Contains(err.Error(), "template style is provided by acme and beta") {
+		t.Errorf("runDeployDryRun() error = %v, want the collision error", err)
+	}
+
+	// Without a policy the first vendor by name wins
+	if err := os.WriteFile("airuler.yaml", []byte("defaults:\n  include_vendors: [\"*\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := captureOutput(func() { err = runDeployDryRun("", "") })
+	if err != nil {
+		t.Fatalf("runDeployDryRun() error = %v", err)
+	}
+	if !strings.Contains(output, "found in multiple sources") || !strings.Contains(output, "Ignoring: beta") {
+		t.Errorf("expected a collision warning ignoring beta, got:\n%s", output)
+	}
+}
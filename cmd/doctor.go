@@ -0,0 +1,668 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	doctorMigrate bool
+	doctorFix     bool
+)
+
+// staleBackupAge is how old an installer backup must be before doctor reports it
+const staleBackupAge = 7 * 24 * time.Hour
+
+// memorySectionMarker separates sections appended to memory files such as CLAUDE.md
+const memorySectionMarker = "<!-- Added by airuler -->"
+
+// backupSuffixPattern matches backups created by the installer, e.g. rule.mdc.backup.20240101-120000
+var backupSuffixPattern = regexp.MustCompile(`\.backup\.\d{8}-\d{6}$`)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check airuler state files and repair common problems",
+	Long: `Doctor inspects the files airuler maintains and reports problems:
+
+- The config directory exists and installation trackers parse
+- State files use the current schema version
+- Every tracked installation path still exists
+- Vendors in airuler.lock are present and at the locked commit
+- include_vendors only names known vendors
+- No stale installer backups are left inside target directories
+- CLAUDE.md memory files contain no duplicate sections
+- compiled/ is not older than the templates it was built from
+
+Each finding that can be repaired automatically is fixed with --fix. Without
+--fix or --migrate doctor only reads; it exits with a non-zero status when any
+problem remains.
+
+Other commands upgrade older state files automatically when they load them. Use
+--migrate to upgrade all of them explicitly; a backup of each original is kept
+next to it as <file>.v<old-version>.backup.
+
+Examples:
+  airuler doctor            # Report problems
+  airuler doctor --fix      # Report and repair problems
+  airuler doctor --migrate  # Upgrade all files to the current schema`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Remaining problems are a result, not a usage mistake
+		cmd.SilenceUsage = true
+		return runDoctor()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorMigrate, "migrate", false, "upgrade state files to the current schema version")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "repair problems that can be fixed automatically")
+}
+
+// doctorFinding is a problem reported by a doctor check
+type doctorFinding struct {
+	message string
+	fixHint string       // what --fix would do; empty if there is no automatic fix
+	fix     func() error // nil if there is no automatic fix
+}
+
+// doctorCheck is a named group of related diagnostics
+type doctorCheck struct {
+	name string
+	run  func() ([]doctorFinding, error)
+}
+
+// versionedFile is a state file governed by a schema
+type versionedFile struct {
+	label   string
+	path    string
+	schema  config.Schema
+	migrate func() (*config.MigrationResult, error)
+}
+
+func runDoctor() error {
+	if doctorMigrate {
+		fmt.Println("🩺 Migrating airuler state files...")
+		files, err := collectVersionedFiles()
+		if err != nil {
+			return err
+		}
+		return runDoctorMigrate(files)
+	}
+
+	fmt.Println("🩺 Running airuler doctor...")
+
+	checks := []doctorCheck{
+		{name: "State files", run: checkStateFiles},
+		{name: "Tracked installations", run: checkTrackedPaths},
+		{name: "Vendors", run: checkLockedVendors},
+		{name: "Included vendors", run: checkIncludeVendors},
+		{name: "Installer backups", run: checkStaleBackups},
+		{name: "Memory files", run: checkDuplicateMemorySections},
+		{name: "Compiled rules", run: checkCompiledUpToDate},
+	}
+
+	problems := 0
+	fixable := 0
+	fixed := 0
+
+	for _, check := range checks {
+		fmt.Printf("\n📋 %s\n", check.name)
+
+		findings, err := check.run()
+		if err != nil {
+			fmt.Printf("  ❌ Check failed: %v\n", err)
+			problems++
+			continue
+		}
+
+		if len(findings) == 0 {
+			fmt.Println("  ✅ OK")
+			continue
+		}
+
+		for _, finding := range findings {
+			problems++
+			fmt.Printf("  ⚠️  %s\n", finding.message)
+
+			if finding.fix == nil {
+				continue
+			}
+			fixable++
+
+			if !doctorFix {
+				fmt.Printf("     🔧 --fix: %s\n", finding.fixHint)
+				continue
+			}
+
+			if err := finding.fix(); err != nil {
+				fmt.Printf("     ❌ Fix failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("     ✅ Fixed: %s\n", finding.fixHint)
+			fixed++
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case problems == 0:
+		fmt.Println("🎉 No problems found")
+	case doctorFix:
+		fmt.Printf("🩺 Found %d problem(s), fixed %d\n", problems, fixed)
+	case fixable > 0:
+		fmt.Printf("🩺 Found %d problem(s); run 'airuler doctor --fix' to repair %d of them\n", problems, fixable)
+	default:
+		fmt.Printf("🩺 Found %d problem(s)\n", problems)
+	}
+
+	if remaining := problems - fixed; remaining > 0 {
+		return fmt.Errorf("%d problem(s) remain", remaining)
+	}
+	return nil
+}
+
+func runDoctorMigrate(files []versionedFile) error {
+	migrated := 0
+	failed := 0
+
+	for _, file := range files {
+		result, err := file.migrate()
+		if err != nil {
+			fmt.Printf("  ❌ %s: %v\n", file.label, err)
+			failed++
+			continue
+		}
+
+		if result.Migrated {
+			fmt.Printf("  ✅ %s: migrated from version %d to %d (backup: %s)\n",
+				file.label, result.FromVersion, result.ToVersion, result.BackupPath)
+			migrated++
+		} else {
+			fmt.Printf("  ✅ %s: already at version %d\n", file.label, result.ToVersion)
+		}
+	}
+
+	fmt.Printf("\n🎉 Migrated %d file(s)", migrated)
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be migrated", failed)
+	}
+	return nil
+}
+
+// collectVersionedFiles lists the state files present for the current invocation
+func collectVersionedFiles() ([]versionedFile, error) {
+	globalTrackerPath, err := config.GlobalInstallationTrackerPath()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []versionedFile{
+		{
+			label:   "Global installation tracker",
+			path:    globalTrackerPath,
+			schema:  config.InstallationTrackerSchema,
+			migrate: config.MigrateGlobalInstallationTracker,
+		},
+	}
+
+	projectDir := GetOriginalWorkingDir()
+	if config.HasProjectInstallationTracker(projectDir) {
+		files = append(files, versionedFile{
+			label:  "Project installation tracker",
+			path:   config.ProjectInstallationTrackerPath(projectDir),
+			schema: config.InstallationTrackerSchema,
+			migrate: func() (*config.MigrationResult, error) {
+				return config.MigrateProjectInstallationTracker(projectDir)
+			},
+		})
+	}
+
+	lockPath, err := filepath.Abs(config.LockFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve lock file path: %w", err)
+	}
+	files = append(files, versionedFile{
+		label:  "Vendor lock file",
+		path:   lockPath,
+		schema: config.LockFileSchema,
+		migrate: func() (*config.MigrationResult, error) {
+			return config.LockFileSchema.MigrateFile(lockPath)
+		},
+	})
+
+	return files, nil
+}
+
+// checkStateFiles verifies the config directory exists and every state file parses at a supported version
+func checkStateFiles() ([]doctorFinding, error) {
+	var findings []doctorFinding
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+		findings = append(findings, doctorFinding{
+			message: fmt.Sprintf("Config directory %s does not exist", configDir),
+			fixHint: "create the config directory",
+			fix:     func() error { return os.MkdirAll(configDir, 0755) },
+		})
+	}
+
+	files, err := collectVersionedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		version, err := file.schema.FileVersion(file.path)
+		if err != nil {
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("%s (%s) cannot be read: %v", file.label, file.path, err),
+			})
+			continue
+		}
+
+		switch {
+		case version > file.schema.Version:
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("%s has version %d, newer than supported version %d; upgrade airuler",
+					file.label, version, file.schema.Version),
+			})
+		case version < file.schema.Version:
+			migrate := file.migrate
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("%s has version %d, current is %d", file.label, version, file.schema.Version),
+				fixHint: "migrate to the current schema (a backup is kept)",
+				fix: func() error {
+					_, err := migrate()
+					return err
+				},
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// checkTrackedPaths reports installation records whose files no longer exist
+func checkTrackedPaths() ([]doctorFinding, error) {
+	tracker, err := readInstallationTrackers()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []doctorFinding
+	for _, record := range tracker.Installations {
+		if record.FilePath == "" {
+			continue
+		}
+		if _, err := os.Stat(record.FilePath); !os.IsNotExist(err) {
+			continue
+		}
+
+		installation := record
+		findings = append(findings, doctorFinding{
+			message: fmt.Sprintf("%s %s: %s is missing", record.Target, record.Rule, record.FilePath),
+			fixHint: "remove the installation record (use 'airuler sync' instead to reinstall)",
+			fix: func() error {
+				return updateTrackerFor(installation.Global, installation.ProjectPath, func(tracker *config.InstallationTracker) error {
+					tracker.RemoveInstallation(installation.Target, installation.Rule,
+						installation.Global, installation.ProjectPath, installation.Mode)
+					return nil
+				})
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+// checkLockedVendors verifies every vendor in airuler.lock is checked out at its locked commit
+func checkLockedVendors() ([]doctorFinding, error) {
+	if _, err := os.Stat(config.LockFileName); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	manager, err := readVendorManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []doctorFinding
+	for _, state := range manager.CheckLockedVendors() {
+		if state.AtLockedCommit() {
+			continue
+		}
+
+		name := state.Name
+		restore := func() error { return manager.RestoreVendor(name) }
+
+		switch {
+		case !state.Present:
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("Vendor %s is missing from vendors/", name),
+				fixHint: "clone it at the locked commit",
+				fix:     restore,
+			})
+		case state.Err != nil:
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("Vendor %s cannot be inspected: %v", name, state.Err),
+			})
+		default:
+			findings = append(findings, doctorFinding{
+				message: fmt.Sprintf("Vendor %s is at %s but airuler.lock has %s",
+					name, shortCommit(state.Commit), shortCommit(state.LockedCommit)),
+				fixHint: "reset it to the locked commit",
+				fix:     restore,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// checkIncludeVendors reports include_vendors entries that do not match any vendor
+func checkIncludeVendors() ([]doctorFinding, error) {
+	includeVendors := viper.GetStringSlice("defaults.include_vendors")
+	if len(includeVendors) == 0 {
+		return nil, nil
+	}
+
+	lockFile, err := config.ReadLockFile(config.LockFileName)
+	if err != nil {
+		return nil, err
+	}
+	available, err := config.GetAvailableVendors(".")
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for name := range lockFile.Vendors {
+		known[name] = true
+	}
+	for _, name := range available {
+		known[name] = true
+	}
+
+	var unknown []string
+	for _, name := range includeVendors {
+		if name != "*" && !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil, nil
+	}
+
+	fixHint := "remove them from include_vendors"
+	if len(unknown) == len(includeVendors) {
+		fixHint += " (an empty list includes all vendors)"
+	}
+
+	return []doctorFinding{{
+		message: fmt.Sprintf("include_vendors lists unknown vendor(s): %s", strings.Join(unknown, ", ")),
+		fixHint: fixHint,
+		fix:     func() error { return removeIncludedVendors(unknown) },
+	}}, nil
+}
+
+// removeIncludedVendors drops names from defaults.include_vendors in the project config file
+func removeIncludedVendors(names []string) error {
+	configPath := viper.ConfigFileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file in use")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	var kept []string
+	for _, name := range cfg.Defaults.IncludeVendors {
+		remove := false
+		for _, unknown := range names {
+			if name == unknown {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, name)
+		}
+	}
+	cfg.Defaults.IncludeVendors = kept
+	viper.Set("defaults.include_vendors", kept)
+
+	return saveProjectConfig(cfg)
+}
+
+// checkStaleBackups reports old installer backups left in directories that hold tracked installations
+func checkStaleBackups() ([]doctorFinding, error) {
+	tracker, err := readInstallationTrackers()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make(map[string]bool)
+	for _, record := range tracker.Installations {
+		if record.FilePath != "" {
+			dirs[filepath.Dir(record.FilePath)] = true
+		}
+	}
+
+	var stale []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !backupSuffixPattern.MatchString(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) < staleBackupAge {
+				continue
+			}
+			stale = append(stale, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(stale)
+
+	findings := make([]doctorFinding, 0, len(stale))
+	for _, path := range stale {
+		backupPath := path
+		findings = append(findings, doctorFinding{
+			message: fmt.Sprintf("Stale backup %s", backupPath),
+			fixHint: "delete the backup",
+			fix:     func() error { return os.Remove(backupPath) },
+		})
+	}
+
+	return findings, nil
+}
+
+// checkDuplicateMemorySections reports memory files that contain the same airuler section more than once
+func checkDuplicateMemorySections() ([]doctorFinding, error) {
+	tracker, err := readInstallationTrackers()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var findings []doctorFinding
+	for _, record := range tracker.Installations {
+		if record.Mode != "memory" || seen[record.FilePath] {
+			continue
+		}
+		seen[record.FilePath] = true
+
+		content, err := os.ReadFile(record.FilePath)
+		if err != nil {
+			continue
+		}
+
+		deduplicated, duplicates := dedupeMemorySections(string(content))
+		if duplicates == 0 {
+			continue
+		}
+
+		path := record.FilePath
+		findings = append(findings, doctorFinding{
+			message: fmt.Sprintf("%s contains %d duplicate airuler section(s)", path, duplicates),
+			fixHint: "remove the duplicate sections",
+			fix:     func() error { return os.WriteFile(path, []byte(deduplicated), 0600) },
+		})
+	}
+
+	return findings, nil
+}
+
+// dedupeMemorySections removes repeated airuler sections from a memory file,
+// keeping the first occurrence. It returns the new content and the number of sections removed.
+func dedupeMemorySections(content string) (string, int) {
+	sections := strings.Split(content, memorySectionMarker)
+
+	seen := make(map[string]bool)
+	kept := []string{strings.TrimSpace(sections[0])}
+	seen[strings.TrimSpace(sections[0])] = true
+	duplicates := 0
+
+	for _, section := range sections[1:] {
+		trimmed := strings.TrimSpace(section)
+		if seen[trimmed] {
+			duplicates++
+			continue
+		}
+		seen[trimmed] = true
+		kept = append(kept, trimmed)
+	}
+
+	if duplicates == 0 {
+		return content, 0
+	}
+
+	return strings.Join(kept, "\n\n"+memorySectionMarker+"\n") + "\n", duplicates
+}
+
+// checkCompiledUpToDate reports when compiled/ is missing or older than its template sources
+func checkCompiledUpToDate() ([]doctorFinding, error) {
+	if !config.IsTemplateDirectory(".") {
+		return nil, nil
+	}
+
+	recompile := func() error {
+		return compileTemplatesWithOutput(compiler.AllTargets, false)
+	}
+
+	if _, err := os.Stat("compiled"); os.IsNotExist(err) {
+		return []doctorFinding{{
+			message: "Templates have not been compiled",
+			fixHint: "compile templates",
+			fix:     recompile,
+		}}, nil
+	}
+
+	manifest, err := config.LoadCompileManifest("compiled")
+	if err != nil {
+		return nil, err
+	}
+	if manifest.CompiledAt.IsZero() {
+		return []doctorFinding{{
+			message: "compiled/ has no provenance manifest (compiled by an older airuler)",
+			fixHint: "recompile templates",
+			fix:     recompile,
+		}}, nil
+	}
+
+	newest, newestPath, err := newestSourceModTime()
+	if err != nil {
+		return nil, err
+	}
+	if !newest.After(manifest.CompiledAt) {
+		return nil, nil
+	}
+
+	return []doctorFinding{{
+		message: fmt.Sprintf("compiled/ is out of date (%s changed after the last compile)", newestPath),
+		fixHint: "recompile templates",
+		fix:     recompile,
+	}}, nil
+}
+
+// newestSourceModTime returns the most recent modification time of the inputs to compilation
+func newestSourceModTime() (time.Time, string, error) {
+	var newest time.Time
+	var newestPath string
+
+	consider := func(path string, info fs.FileInfo) {
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+			newestPath = path
+		}
+	}
+
+	for _, file := range []string{"airuler.yaml", config.LockFileName} {
+		if info, err := os.Stat(file); err == nil {
+			consider(file, info)
+		}
+	}
+
+	for _, root := range []string{"templates", "vendors"} {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if entry.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			consider(path, info)
+			return nil
+		})
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+	}
+
+	return newest, newestPath, nil
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+func TestDedupeMemorySections(t *testing.T) {
+	t.Run("no duplicates", func(t *testing.T) {
+		content := "# My notes\n\n" + memorySectionMarker + "\n# Rule A\n"
+		got, duplicates := dedupeMemorySections(content)
+		if duplicates != 0 || got != content {
+			t.Errorf("dedupeMemorySections() = %q, %d; want content unchanged", got, duplicates)
+		}
+	})
+
+	t.Run("removes repeated sections", func(t *testing.T) {
+		content := "# My notes\n\n" +
+			memorySectionMarker + "\n# Rule A\n\n" +
+			memorySectionMarker + "\n# Rule B\n\n" +
+			memorySectionMarker + "\n# Rule A\n"
+
+		got, duplicates := dedupeMemorySections(content)
+		if duplicates != 1 {
+			t.Errorf("duplicates = %d, want 1", duplicates)
+		}
+		if strings.Count(got, "# Rule A") != 1 || strings.Count(got, "# Rule B") != 1 {
+			t.Errorf("unexpected deduplicated content:\n%s", got)
+		}
+		if !strings.HasPrefix(got, "# My notes") {
+			t.Errorf("user content before the first marker was not kept:\n%s", got)
+		}
+	})
+}
+
+func TestCheckStaleBackupsAndTrackedPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	rulesDir := t.TempDir()
+	installed := filepath.Join(rulesDir, "rule.mdc")
+	if err := os.WriteFile(installed, []byte("rule"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldBackup := filepath.Join(rulesDir, "rule.mdc.backup.20240101-120000")
+	freshBackup := filepath.Join(rulesDir, "rule.mdc.backup.20990101-120000")
+	for _, path := range []string{oldBackup, freshBackup} {
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-2 * staleBackupAge)
+	if err := os.Chtimes(oldBackup, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	err := config.UpdateGlobalInstallationTracker(func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(config.InstallationRecord{Target: "cursor", Rule: "rule", Global: true, FilePath: installed})
+		tracker.AddInstallation(config.InstallationRecord{Target: "cursor", Rule: "gone", Global: true,
+			FilePath: filepath.Join(rulesDir, "gone.mdc")})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := checkStaleBackups()
+	if err != nil {
+		t.Fatalf("checkStaleBackups() error = %v", err)
+	}
+	if len(findings) != 1 || !strings.Contains(findings[0].message, oldBackup) {
+		t.Fatalf("checkStaleBackups() = %+v, want one finding for %s", findings, oldBackup)
+	}
+	if err := findings[0].fix(); err != nil {
+		t.Fatalf("fix error = %v", err)
+	}
+	if _, err := os.Stat(oldBackup); !os.IsNotExist(err) {
+		t.Error("stale backup was not removed")
+	}
+	if _, err := os.Stat(freshBackup); err != nil {
+		t.Error("recent backup should be kept")
+	}
+
+	findings, err = checkTrackedPaths()
+	if err != nil {
+		t.Fatalf("checkTrackedPaths() error = %v", err)
+	}
+	if len(findings) != 1 || !strings.Contains(findings[0].message, "gone.mdc") {
+		t.Fatalf("checkTrackedPaths() = %+v, want one finding for gone.mdc", findings)
+	}
+	if err := findings[0].fix(); err != nil {
+		t.Fatalf("fix error = %v", err)
+	}
+
+	tracker, err := config.LoadGlobalInstallationTracker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.Installations) != 1 || tracker.Installations[0].Rule != "rule" {
+		t.Errorf("expected only the existing installation to remain, got %+v", tracker.Installations)
+	}
+}
+
+func TestRunDoctorReadOnly(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	trackerPath := filepath.Join(configDir, "airuler.installs")
+	original := []byte("installations:\n  - target: cursor\n    rule: gone\n    global: true\n    file_path: /nonexistent/gone.mdc\n")
+	if err := os.WriteFile(trackerPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var doctorErr error
+	output := captureOutput(func() { doctorErr = runDoctor() })
+	if doctorErr == nil {
+		t.Errorf("runDoctor() error = nil, want an error for remaining problems; output:\n%s", output)
+	}
+
+	data, err := os.ReadFile(trackerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("doctor without --fix rewrote the tracker:\n%s", data)
+	}
+	backups, _ := filepath.Glob(trackerPath + ".v*.backup")
+	if len(backups) != 0 {
+		t.Errorf("doctor without --fix created backups: %v", backups)
+	}
+	if !strings.Contains(output, "gone.mdc") {
+		t.Errorf("expected the missing tracked path to be reported, got:\n%s", output)
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+)
+
+// Sync hook stages, as named in the hooks section of airuler.yaml
+const (
+	hookPreCompile  = "pre_compile"
+	hookPostCompile = "post_compile"
+	hookPreDeploy   = "pre_deploy"
+	hookPostDeploy  = "post_deploy"
+)
+
+// hookContext describes the sync run to hook commands
+type hookContext struct {
+	targets      []compiler.Target
+	changedFiles []string // files added or modified by the preceding step
+}
+
+// hookCommands returns the commands configured for a stage
+func hookCommands(hooks config.HooksConfig, stage string) []string {
+	switch stage {
+	case hookPreCompile:
+		return hooks.PreCompile
+	case hookPostCompile:
+		return hooks.PostCompile
+	case hookPreDeploy:
+		return hooks.PreDeploy
+	case hookPostDeploy:
+		return hooks.PostDeploy
+	}
+	return nil
+}
+
+// runHooks runs the commands of a stage in order from the template directory, stopping at the
+// first command that fails. Hook output goes to stdout, which is stderr with --output json.
+func runHooks(hooks config.HooksConfig, stage string, context hookContext) error {
+	commands := hookCommands(hooks, stage)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	env := append(os.Environ(), hookEnv(stage, currentDir, context)...)
+
+	// Each stage with commands is a step of the sync result
+	return runStep(stage, func() error {
+		for _, command := range commands {
+			fmt.Printf("🪝 Running %s hook: %s\n", stage, command)
+
+			cmd := shellCommand(command)
+			cmd.Dir = currentDir
+			cmd.Env = env
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("%s hook '%s' failed: %w", stage, command, err)
+			}
+		}
+		return nil
+	})
+}
+
+// hookEnv returns the environment variables that describe a sync stage to its hooks
+func hookEnv(stage, projectDir string, context hookContext) []string {
+	targets := make([]string, len(context.targets))
+	for i, target := range context.targets {
+		targets[i] = string(target)
+	}
+
+	return []string{
+		"AIRULER_HOOK=" + stage,
+		"AIRULER_PROJECT_DIR=" + projectDir,
+		"AIRULER_TARGETS=" + strings.Join(targets, ","),
+		"AIRULER_SCOPE=" + syncScope,
+		"AIRULER_CHANGED_FILES=" + strings.Join(context.changedFiles, "\n"),
+	}
+}
+
+// shellCommand runs a hook command through the platform's shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// snapshotFiles returns the SHA256 hash of every file below dir, keyed by path. A missing
+// directory has no files.
+func snapshotFiles(dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		hash, err := calculateFileHash(path)
+		if err != nil {
+			return err
+		}
+		hashes[path] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return hashes, nil
+}
+
+// changedFilesSince lists the files below dir that were added or modified since a snapshot,
+// leaving out the compile manifest
+func changedFilesSince(dir string, before map[string]string) ([]string, error) {
+	after, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, hash := range after {
+		if filepath.Base(path) == config.CompileManifestFileName {
+			continue
+		}
+		if before[path] != hash {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
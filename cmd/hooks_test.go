@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+	t.Chdir(t.TempDir())
+
+	context := hookContext{
+		targets:      []compiler.Target{compiler.TargetClaude, compiler.TargetCursor},
+		changedFiles: []string{"compiled/claude/a.md", "compiled/cursor/a.mdc"},
+	}
+
+	t.Run("commands run in order with the stage environment", func(t *testing.T) {
+		hooks := config.HooksConfig{PostCompile: []string{
+			`printf '%s|%s|%s\n' "$AIRULER_HOOK" "$AIRULER_TARGETS" "$AIRULER_SCOPE" > hook.log`,
+			`printf '%s\n' "$AIRULER_CHANGED_FILES" >> hook.log`,
+			`test "$AIRULER_PROJECT_DIR" = "$(pwd)" && echo same-dir >> hook.log`,
+		}}
+
+		captureOutput(func() {
+			if err := runHooks(hooks, hookPostCompile, context); err != nil {
+				t.Errorf("runHooks() error = %v", err)
+			}
+		})
+
+		content, err := os.ReadFile("hook.log")
+		if err != nil {
+			t.Fatalf("hook did not run: %v", err)
+		}
+		want := "post_compile|claude,cursor|all\ncompiled/claude/a.md\ncompiled/cursor/a.mdc\nsame-dir\n"
+		if string(content) != want {
+			t.Errorf("hook output = %q, want %q", content, want)
+		}
+	})
+
+	t.Run("a failing command stops the stage", func(t *testing.T) {
+		hooks := config.HooksConfig{PreDeploy: []string{"exit 3", "touch ran-after-failure"}}
+
+		var err error
+		captureOutput(func() { err = runHooks(hooks, hookPreDeploy, context) })
+		if err == nil || !strings.Contains(err.Error(), "pre_deploy hook 'exit 3' failed: exit status 3") {
+			t.Errorf("runHooks() error = %v", err)
+		}
+		if _, err := os.Stat("ran-after-failure"); !os.IsNotExist(err) {
+			t.Error("commands after a failing hook should not run")
+		}
+	})
+
+	t.Run("stages without commands do nothing", func(t *testing.T) {
+		hooks := config.HooksConfig{PreCompile: []string{"exit 1"}}
+		if err := runHooks(hooks, hookPostDeploy, context); err != nil {
+			t.Errorf("runHooks() error = %v", err)
+		}
+	})
+}
+
+func TestChangedFilesSince(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "compiled")
+
+	// A missing directory has no files
+	before, err := snapshotFiles(dir)
+	if err != nil || len(before) != 0 {
+		t.Fatalf("snapshotFiles() = %v, %v; want no files", before, err)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("claude/same.md", "same")
+	write("claude/edited.md", "old")
+	write("claude/removed.md", "gone")
+	before, err = snapshotFiles(dir)
+	if err != nil {
+		t.Fatalf("snapshotFiles() error = %v", err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to clean directory: %v", err)
+	}
+	write("claude/same.md", "same")
+	write("claude/edited.md", "new")
+	write("cursor/added.mdc", "added")
+
+	changed, err := changedFilesSince(dir, before)
+	if err != nil {
+		t.Fatalf("changedFilesSince() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "claude", "edited.md"), filepath.Join(dir, "cursor", "added.mdc")}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("changedFilesSince() = %v, want %v", changed, want)
+	}
+}
+
+func TestRunSyncHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	originalNoUpdate, originalAllowDirty, originalTargets := syncNoUpdate, syncAllowDirty, syncTargets
+	syncNoUpdate, syncAllowDirty, syncTargets = true, true, "claude"
+	t.Cleanup(func() {
+		syncNoUpdate, syncAllowDirty, syncTargets = originalNoUpdate, originalAllowDirty, originalTargets
+	})
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("templates", "greet.tmpl"), []byte("Hello"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	writeHooks := func(hooks string) {
+		t.Helper()
+		if err := os.WriteFile("airuler.yaml", []byte("hooks:\n"+hooks), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	viper.SetConfigFile("airuler.yaml")
+	t.Cleanup(viper.Reset)
+
+	writeHooks(`  pre_compile: ["echo pre_compile >> stages.log"]
+  post_compile: ["echo \"post_compile $AIRULER_TARGETS $AIRULER_CHANGED_FILES\" >> stages.log"]
+  pre_deploy: ["echo pre_deploy >> stages.log"]
+  post_deploy: ["echo post_deploy >> stages.log"]
+`)
+	var err error
+	captureOutput(func() { err = runSync("") })
+	if err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	content, err := os.ReadFile("stages.log")
+	if err != nil {
+		t.Fatalf("hooks did not run: %v", err)
+	}
+	want := "pre_compile\npost_compile claude compiled/claude/greet.md\npre_deploy\npost_deploy\n"
+	if string(content) != want {
+		t.Errorf("hook log = %q, want %q", content, want)
+	}
+
+	// Unchanged output is not reported again, and a failing hook aborts the sync
+	writeHooks(`  post_compile: ["echo \"changed: $AIRULER_CHANGED_FILES\" > stages.log"]
+  pre_deploy: ["exit 1"]
+  post_deploy: ["echo post_deploy >> stages.log"]
+`)
+	captureOutput(func() { err = runSync("") })
+	if err == nil || !strings.Contains(err.Error(), "sync aborted: pre_deploy hook 'exit 1' failed") {
+		t.Errorf("runSync() error = %v, want the failing hook reported", err)
+	}
+	content, _ = os.ReadFile("stages.log")
+	if string(content) != "changed: \n" {
+		t.Errorf("hook log = %q, want no changed files and no post_deploy hook", content)
+	}
+}
@@ -4,11 +4,9 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
@@ -143,9 +141,7 @@ vendor:
 	}
 
 	// Create empty lock file
-	lockFile := &airulerconfig.LockFile{
-		Vendors: make(map[string]airulerconfig.VendorLock),
-	}
+	lockFile := airulerconfig.NewLockFile()
 	lockData, err := yaml.Marshal(lockFile)
 	if err != nil {
 		return fmt.Errorf("failed to marshal lock file: %w", err)
@@ -458,8 +454,11 @@ description: "Standard footer component"
 
 	// Ask user if they want to initialize git repository (skip in test mode)
 	if os.Getenv("AIRULER_TEST_MODE") == "" {
-		initGit := askYesNo("Initialize git repository? (y/n)")
-		if initGit {
+		initGit, err := prompts.Confirm("Initialize git repository?")
+		if err != nil {
+			// The repository is optional, so a missing answer does not fail init
+			fmt.Printf("⏭️  Skipping git repository initialization: %v\n", err)
+		} else if initGit {
 			if err := initializeGitRepo(); err != nil {
 				fmt.Printf("⚠️  Warning: Failed to initialize git repository: %v\n", err)
 			}
@@ -508,105 +507,6 @@ description: "Standard footer component"
 	return nil
 }
 
-// askYesNo prompts the user for a yes/no question and returns true for yes
-// If stdin is not available for interactive input (common in automated environments),
-// it defaults to 'no' to avoid hanging the program
-func askYesNo(prompt string) bool {
-	// Check if we have a proper terminal for interactive input
-	fileInfo, err := os.Stdin.Stat()
-	if err != nil {
-		fmt.Printf("Warning: Cannot check stdin availability: %v. Defaulting to 'no'.\n", err)
-		return false
-	}
-
-	// If stdin is not a character device (e.g., piped input), handle it differently
-	if (fileInfo.Mode() & os.ModeCharDevice) == 0 {
-		// We have piped input, try to read it
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print(prompt + " ")
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("Warning: Could not read piped input: %v. Defaulting to 'no'.\n", err)
-			return false
-		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		return response == "y" || response == "yes"
-	}
-
-	// We have a terminal, try interactive input
-	fmt.Print(prompt + " ")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		// Handle EOF or other stdin errors gracefully
-		fmt.Printf("\nWarning: No input available (stdin closed). Defaulting to 'no'.\n")
-		fmt.Println("Hint: Run 'airuler init' in a proper terminal for interactive prompts.")
-		return false
-	}
-
-	response = strings.TrimSpace(strings.ToLower(response))
-	return response == "y" || response == "yes"
-}
-
-// askString prompts the user for a string input with an optional default value
-func askString(prompt, defaultValue string) string {
-	// Check if we have a proper terminal for interactive input
-	fileInfo, err := os.Stdin.Stat()
-	if err != nil {
-		fmt.Printf("Warning: Cannot check stdin availability: %v. Using default value.\n", err)
-		return defaultValue
-	}
-
-	// If stdin is not a character device (e.g., piped input), handle it differently
-	if (fileInfo.Mode() & os.ModeCharDevice) == 0 {
-		// We have piped input, try to read it
-		reader := bufio.NewReader(os.Stdin)
-		if defaultValue != "" {
-			fmt.Printf("%s [%s]: ", prompt, defaultValue)
-		} else {
-			fmt.Printf("%s: ", prompt)
-		}
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("Warning: Could not read piped input: %v. Using default value.\n", err)
-			return defaultValue
-		}
-		response = strings.TrimSpace(response)
-		if response == "" {
-			return defaultValue
-		}
-		return response
-	}
-
-	// We have a terminal, try interactive input
-	for {
-		if defaultValue != "" {
-			fmt.Printf("%s [%s]: ", prompt, defaultValue)
-		} else {
-			fmt.Printf("%s: ", prompt)
-		}
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			// Handle EOF or other stdin errors gracefully
-			fmt.Printf("\nWarning: No input available (stdin closed). Using default value.\n")
-			fmt.Println("Hint: Run 'airuler init' in a proper terminal for interactive prompts.")
-			return defaultValue
-		}
-
-		response = strings.TrimSpace(response)
-		if response == "" {
-			if defaultValue != "" {
-				return defaultValue
-			}
-			fmt.Println("This field is required. Please enter a value.")
-			continue
-		}
-		return response
-	}
-}
-
 // promptForUserInfo prompts the user for missing git user name and/or email
 func promptForUserInfo(existingUser *git.User) (*git.User, error) {
 	// Start with existing values or empty strings
@@ -635,7 +535,10 @@ func promptForUserInfo(existingUser *git.User) (*git.User, error) {
 
 	// Only prompt for name if it's missing or invalid
 	if needsName {
-		name = askString("Git user name", name)
+		var err error
+		if name, err = prompts.Ask("Git user name", name); err != nil {
+			return nil, err
+		}
 		if !git.IsValidName(name) {
 			return nil, fmt.Errorf("invalid name: must be at least 2 characters long")
 		}
@@ -643,7 +546,10 @@ func promptForUserInfo(existingUser *git.User) (*git.User, error) {
 
 	// Only prompt for email if it's missing or invalid
 	if needsEmail {
-		email = askString("Git user email", email)
+		var err error
+		if email, err = prompts.Ask("Git user email", email); err != nil {
+			return nil, err
+		}
 		if !git.IsValidEmail(email) {
 			return nil, fmt.Errorf("invalid email format")
 		}
@@ -87,6 +87,7 @@ func installRules() error {
 		count, err := installForTarget(target)
 		if err != nil {
 			fmt.Printf("Warning: failed to install for %s: %v\n", target, err)
+			recordRuleFailure(ruleResult{Target: string(target), Rule: installRule, Project: installProject}, err)
 			continue
 		}
 		installed += count
@@ -139,6 +140,10 @@ func installForTarget(target compiler.Target) (int, error) {
 
 		sourcePath := filepath.Join(compiledDir, file.Name())
 
+		// The template name is the filename without the target-specific extension
+		baseName := strings.TrimSuffix(strings.TrimSuffix(file.Name(), ".md"), ".mdc")
+		result := ruleResult{Target: string(target), Rule: baseName}
+
 		// Determine mode from filename for Claude target only
 		mode := "" // default for non-Claude targets
 		if target == compiler.TargetClaude {
@@ -155,37 +160,42 @@ func installForTarget(target compiler.Target) (int, error) {
 			resolvedPath, resolveErr := resolveProjectPath(installProject)
 			if resolveErr != nil {
 				fmt.Printf("  ⚠️  Failed to resolve project path for %s: %v\n", file.Name(), resolveErr)
+				recordRuleFailure(result, resolveErr)
 				continue
 			}
+			result.Project = resolvedPath
 			targetDir, err = getProjectInstallDirForMode(target, resolvedPath, mode)
 		} else {
 			targetDir, err = getGlobalInstallDirForMode(target, mode)
 		}
 		if err != nil {
 			fmt.Printf("  ⚠️  Failed to get install directory for %s: %v\n", file.Name(), err)
+			recordRuleFailure(result, err)
 			continue
 		}
 
 		// Ensure target directory exists
 		if err := os.MkdirAll(targetDir, 0755); err != nil {
 			fmt.Printf("  ⚠️  Failed to create target directory %s: %v\n", targetDir, err)
+			recordRuleFailure(result, err)
 			continue
 		}
 
 		targetPath := filepath.Join(targetDir, file.Name())
+		result.Mode, result.Path = mode, targetPath
+		result.Status = installStatus(sourcePath, targetPath, mode)
 
 		if err := installFileWithMode(sourcePath, targetPath, target, mode); err != nil {
 			fmt.Printf("  ⚠️  Failed to install %s: %v\n", file.Name(), err)
+			recordRuleFailure(result, err)
 			continue
 		}
+		recordRule(result)
 
 		// Record the installation
 		ruleName := installRule
 		if ruleName == "" {
 			// When installing all templates, use the actual template name from filename
-			// Remove the target-specific extension to get the base template name
-			baseName := strings.TrimSuffix(file.Name(), ".md")
-			baseName = strings.TrimSuffix(baseName, ".mdc")
 			ruleName = baseName
 		}
 		if err := recordInstallation(target, ruleName, targetPath, mode); err != nil {
@@ -233,6 +243,9 @@ func installCopilotRules(compiledDir string, files []os.DirEntry) (int, error) {
 			content, err := os.ReadFile(sourcePath)
 			if err != nil {
 				fmt.Printf("  ⚠️  Failed to read %s: %v\n", file.Name(), err)
+				recordRuleFailure(ruleResult{
+					Target: string(compiler.TargetCopilot), Rule: strings.TrimSuffix(file.Name(), ".copilot-instructions.md"), Project: absPath,
+				}, err)
 				continue
 			}
 
@@ -246,7 +259,7 @@ func installCopilotRules(compiledDir string, files []os.DirEntry) (int, error) {
 	}
 
 	// Get existing rules from installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers(installProject)
 	if err != nil {
 		return 0, fmt.Errorf("failed to load installation tracker: %w", err)
 	}
@@ -328,6 +341,7 @@ func installCopilotRules(compiledDir string, files []os.DirEntry) (int, error) {
 	if err := os.WriteFile(targetPath, []byte(combinedContent.String()), 0600); err != nil {
 		return 0, fmt.Errorf("failed to write copilot instructions: %w", err)
 	}
+	recordCombinedRules(compiler.TargetCopilot, newRuleNames, existingRuleNames, targetPath, absPath)
 
 	// Record installation for each NEW template that was added
 	var newlyInstalledCount int
@@ -395,6 +409,7 @@ func installGeminiRules(compiledDir string, files []os.DirEntry) (int, error) {
 			content, err := os.ReadFile(sourcePath)
 			if err != nil {
 				fmt.Printf("  ⚠️  Failed to read %s: %v\n", file.Name(), err)
+				recordRuleFailure(ruleResult{Target: string(compiler.TargetGemini), Rule: strings.TrimSuffix(file.Name(), ".md")}, err)
 				continue
 			}
 
@@ -408,7 +423,7 @@ func installGeminiRules(compiledDir string, files []os.DirEntry) (int, error) {
 	}
 
 	// Get existing rules from installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers(installProject)
 	if err != nil {
 		return 0, fmt.Errorf("failed to load installation tracker: %w", err)
 	}
@@ -501,6 +516,7 @@ func installGeminiRules(compiledDir string, files []os.DirEntry) (int, error) {
 	if err := os.WriteFile(targetPath, []byte(combinedContent.String()), 0600); err != nil {
 		return 0, fmt.Errorf("failed to write Gemini instructions: %w", err)
 	}
+	recordCombinedRules(compiler.TargetGemini, newRuleNames, existingRuleNames, targetPath, projectPath)
 
 	// Record installation for each NEW template that was added
 	var newlyInstalledCount int
@@ -548,6 +564,18 @@ func installGeminiRules(compiledDir string, files []os.DirEntry) (int, error) {
 	return 1, nil
 }
 
+// recordCombinedRules records the rules merged into a single instructions file; rules that
+// were already in it are rewritten from their compiled output
+func recordCombinedRules(target compiler.Target, ruleNames, existingRuleNames []string, path, project string) {
+	for _, ruleName := range ruleNames {
+		status := ruleInstalled
+		if slices.Contains(existingRuleNames, ruleName) {
+			status = ruleUpdated
+		}
+		recordRule(ruleResult{Target: string(target), Rule: ruleName, Status: status, Path: path, Project: project})
+	}
+}
+
 func installFile(source, target string, _ compiler.Target) error {
 	// Check if target exists and create backup
 	if _, err := os.Stat(target); err == nil && !installForce {
@@ -742,18 +770,20 @@ func installMemoryFile(source, target string) error {
 			return fmt.Errorf("failed to read existing file: %w", err)
 		}
 
-		// Combine content with separator
-		combinedContent := strings.TrimSpace(string(existingContent)) + "\n\n" +
-			"<!-- Added by airuler -->\n" +
-			strings.TrimSpace(string(newContent)) + "\n"
-
 		// Write combined content
-		return os.WriteFile(target, []byte(combinedContent), 0600)
+		return os.WriteFile(target, appendMemoryContent(existingContent, newContent), 0600)
 	}
 	// File doesn't exist - create new
 	return os.WriteFile(target, newContent, 0600)
 }
 
+// appendMemoryContent appends new memory content to an existing memory file with a separator
+func appendMemoryContent(existingContent, newContent []byte) []byte {
+	return []byte(strings.TrimSpace(string(existingContent)) + "\n\n" +
+		"<!-- Added by airuler -->\n" +
+		strings.TrimSpace(string(newContent)) + "\n")
+}
+
 func recordInstallation(target compiler.Target, rule, filePath, mode string) error {
 	// Convert project path to absolute path if it's a project installation
 	var projectPath string
@@ -773,35 +803,16 @@ func recordInstallation(target compiler.Target, rule, filePath, mode string) err
 		Mode:        mode,
 		FilePath:    filePath,
 		InstalledAt: time.Now(),
+		Provenance:  lookupProvenance(string(target), rule),
 	}
 
-	var tracker *config.InstallationTracker
-	var err error
-
-	if installProject == "" {
-		// Global installation
-		tracker, err = config.LoadGlobalInstallationTracker()
-		if err != nil {
-			return fmt.Errorf("failed to load global installation tracker: %w", err)
-		}
-
-		tracker.AddInstallation(record)
-
-		if err := config.SaveGlobalInstallationTracker(tracker); err != nil {
-			return fmt.Errorf("failed to save global installation tracker: %w", err)
-		}
-	} else {
-		// Project installation
-		tracker, err = config.LoadProjectInstallationTracker()
-		if err != nil {
-			return fmt.Errorf("failed to load project installation tracker: %w", err)
-		}
-
+	addRecord := func(tracker *config.InstallationTracker) error {
 		tracker.AddInstallation(record)
+		return nil
+	}
 
-		if err := config.SaveProjectInstallationTracker(tracker); err != nil {
-			return fmt.Errorf("failed to save project installation tracker: %w", err)
-		}
+	if err := updateTrackerFor(record.Global, projectPath, addRecord); err != nil {
+		return fmt.Errorf("failed to update installation tracker: %w", err)
 	}
 
 	return nil
@@ -888,6 +899,9 @@ func runInteractiveInstall() error {
 	}
 
 	// Run interactive selection
+	if err := requireInteractive("interactive installation"); err != nil {
+		return err
+	}
 	selectedItems, cancelled, err := ui.RunInteractiveSelection(config)
 	if err != nil {
 		return fmt.Errorf("interactive selection failed: %w", err)
@@ -929,7 +943,10 @@ func loadAvailableTemplates() ([]installSelectionItem, error) {
 	var items []installSelectionItem
 
 	// Load current installations to check what's already installed
-	tracker, _ := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers(installProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load installation tracker: %w", err)
+	}
 	installations := tracker.GetInstallations("", "")
 
 	// Create a map for quick lookup of installed templates
@@ -1186,63 +1203,47 @@ func (f fakeFileInfo) IsDir() bool                { return false }
 func (f fakeFileInfo) Type() os.FileMode          { return f.Mode() }
 func (f fakeFileInfo) Info() (os.FileInfo, error) { return f.FileInfo, nil }
 
-// updateSingleInstallationWithStatus updates a single installation and returns status
-func updateSingleInstallationWithStatus(installation config.InstallationRecord) (string, error) {
+// updateSingleInstallationWithStatus updates a single installation and returns its status and the files it wrote
+func updateSingleInstallationWithStatus(installation config.InstallationRecord) (string, []string, error) {
 	target := compiler.Target(installation.Target)
 
 	// Validate target
 	if !isValidTarget(target) {
-		return "failed", fmt.Errorf("invalid target: %s", installation.Target)
+		return "failed", nil, fmt.Errorf("invalid target: %s", installation.Target)
 	}
 
 	// Find the compiled rule files
 	compiledDir := filepath.Join("compiled", string(target))
 	files, err := os.ReadDir(compiledDir)
 	if err != nil {
-		return "failed", fmt.Errorf("failed to read compiled directory: %w", err)
+		return "failed", nil, fmt.Errorf("failed to read compiled directory: %w", err)
 	}
 
-	var sourceFiles []string
-	if installation.Rule == "*" {
-		// For wildcard installations, install all files for this target
-		for _, file := range files {
-			if !file.IsDir() {
-				sourceFiles = append(sourceFiles, filepath.Join(compiledDir, file.Name()))
-			}
-		}
-	} else {
-		// Find the specific compiled rule file
-		for _, file := range files {
-			if strings.Contains(file.Name(), installation.Rule) {
-				sourceFiles = append(sourceFiles, filepath.Join(compiledDir, file.Name()))
-				break
-			}
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() {
+			names = append(names, file.Name())
 		}
 	}
 
-	if len(sourceFiles) == 0 {
-		return "failed", fmt.Errorf("no compiled rules found for %s", installation.Rule)
+	var sourceFiles []string
+	for _, name := range installationFiles(installation.Rule, names) {
+		sourceFiles = append(sourceFiles, filepath.Join(compiledDir, name))
 	}
 
-	// Determine the target directory based on the original installation
-	var targetDir string
-
-	if installation.Global {
-		targetDir, err = getGlobalInstallDirForMode(target, installation.Mode)
-	} else {
-		if installation.ProjectPath == "" {
-			return "failed", fmt.Errorf("project path not specified for project installation")
-		}
-		targetDir, err = getProjectInstallDirForMode(target, installation.ProjectPath, installation.Mode)
+	if len(sourceFiles) == 0 {
+		return "failed", nil, fmt.Errorf("no compiled rules found for %s", installation.Rule)
 	}
 
+	// Determine the target directory based on the original installation
+	targetDir, err := installationTargetDir(installation)
 	if err != nil {
-		return "failed", fmt.Errorf("failed to get target directory: %w", err)
+		return "failed", nil, err
 	}
 
 	// Ensure target directory exists
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return "failed", fmt.Errorf("failed to create target directory: %w", err)
+		return "failed", nil, fmt.Errorf("failed to create target directory: %w", err)
 	}
 
 	// For update-installed, we always force overwrite since we're updating
@@ -1253,6 +1254,7 @@ func updateSingleInstallationWithStatus(installation config.InstallationRecord)
 	// Install all the files (only if they have changed)
 	filesChanged := false
 	filesInstalled := false
+	var written []string
 	for _, sourceFile := range sourceFiles {
 		targetPath := filepath.Join(targetDir, filepath.Base(sourceFile))
 
@@ -1262,11 +1264,12 @@ func updateSingleInstallationWithStatus(installation config.InstallationRecord)
 
 		// Check if file has changed before replacing
 		if hasFileChanged, err := hasFileContentChanged(sourceFile, targetPath); err != nil {
-			return "failed", fmt.Errorf("failed to check file changes for %s: %w", filepath.Base(sourceFile), err)
+			return "failed", nil, fmt.Errorf("failed to check file changes for %s: %w", filepath.Base(sourceFile), err)
 		} else if hasFileChanged {
 			if err := installFileWithMode(sourceFile, targetPath, target, installation.Mode); err != nil {
-				return "failed", fmt.Errorf("failed to install file %s: %w", filepath.Base(sourceFile), err)
+				return "failed", written, fmt.Errorf("failed to install file %s: %w", filepath.Base(sourceFile), err)
 			}
+			written = append(written, targetPath)
 			if !fileExists {
 				filesInstalled = true
 			} else {
@@ -1283,7 +1286,7 @@ func updateSingleInstallationWithStatus(installation config.InstallationRecord)
 			// Don't fail the whole operation for this, just warn
 			fmt.Printf("    Warning: failed to update installation record: %v\n", err)
 		}
-		return "installed", nil
+		return "installed", written, nil
 	} else if filesChanged {
 		// Update timestamp to current time for changed files
 		installation.InstalledAt = time.Now()
@@ -1291,35 +1294,59 @@ func updateSingleInstallationWithStatus(installation config.InstallationRecord)
 			// Don't fail the whole operation for this, just warn
 			fmt.Printf("    Warning: failed to update installation record: %v\n", err)
 		}
-		return "updated", nil
+		return "updated", written, nil
 	}
 
-	return "unchanged", nil
+	return "unchanged", nil, nil
 }
 
-func updateInstallationRecord(installation config.InstallationRecord) error {
-	var tracker *config.InstallationTracker
-	var err error
+// installationFiles returns the compiled files an installation of rule covers: every file for
+// the wildcard rule, otherwise the first file whose name contains the rule
+func installationFiles(rule string, names []string) []string {
+	if rule == "*" {
+		return names
+	}
+	for _, name := range names {
+		if strings.Contains(name, rule) {
+			return []string{name}
+		}
+	}
+	return nil
+}
+
+// installationTargetDir returns the directory the files of an existing installation go to
+func installationTargetDir(installation config.InstallationRecord) (string, error) {
+	target := compiler.Target(installation.Target)
 
+	var targetDir string
+	var err error
 	if installation.Global {
-		tracker, err = config.LoadGlobalInstallationTracker()
-		if err != nil {
-			return err
-		}
+		targetDir, err = getGlobalInstallDirForMode(target, installation.Mode)
 	} else {
-		tracker, err = config.LoadProjectInstallationTracker()
-		if err != nil {
-			return err
+		if installation.ProjectPath == "" {
+			return "", fmt.Errorf("project path not specified for project installation")
 		}
+		targetDir, err = getProjectInstallDirForMode(target, installation.ProjectPath, installation.Mode)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get target directory: %w", err)
 	}
+	return targetDir, nil
+}
 
-	// Update the installation record
-	tracker.AddInstallation(installation) // This will replace the existing record
+func updateInstallationRecord(installation config.InstallationRecord) error {
+	// Refresh provenance from the freshly compiled rules
+	if provenance := lookupProvenance(installation.Target, installation.Rule); provenance.TemplateHash != "" {
+		installation.Provenance = provenance
+	}
 
-	if installation.Global {
-		return config.SaveGlobalInstallationTracker(tracker)
+	// Update the installation record (AddInstallation replaces the existing record)
+	replaceRecord := func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(installation)
+		return nil
 	}
-	return config.SaveProjectInstallationTracker(tracker)
+
+	return updateTrackerFor(installation.Global, installation.ProjectPath, replaceRecord)
 }
 
 // hasFileContentChanged compares the SHA256 hash of source and target files
@@ -1348,6 +1375,20 @@ func hasFileContentChanged(sourceFile, targetFile string) (bool, error) {
 	return sourceHash != targetHash, nil
 }
 
+// installStatus reports whether installing source to target is a new installation, an update
+// or leaves the target unchanged. Memory files are appended to, so an existing one is updated.
+func installStatus(source, target, mode string) string {
+	if _, err := os.Stat(target); err != nil {
+		return ruleInstalled
+	}
+	if mode != "memory" {
+		if changed, err := hasFileContentChanged(source, target); err == nil && !changed {
+			return ruleUnchanged
+		}
+	}
+	return ruleUpdated
+}
+
 // calculateFileHash computes SHA256 hash of a file
 func calculateFileHash(filePath string) (string, error) {
 	file, err := os.Open(filePath)
@@ -1366,59 +1407,35 @@ func calculateFileHash(filePath string) (string, error) {
 
 // runListInstalled displays all installed templates (used by manage command)
 func runListInstalled() error {
-	// Load global installation tracker
-	globalTracker, err := config.LoadGlobalInstallationTracker()
+	// Load global and in-project installation trackers
+	tracker, err := loadInstallationTrackers()
 	if err != nil {
-		return fmt.Errorf("failed to load global installation tracker: %w", err)
+		return fmt.Errorf("failed to load installation tracker: %w", err)
 	}
 
-	// Load project installation tracker if in a project
-	var projectTracker *config.InstallationTracker
-	projectTracker, _ = config.LoadProjectInstallationTracker()
-
 	// Collect and deduplicate installations
 	uniqueMap := make(map[string]uniqueInstall)
 
-	// Process global installations
-	for _, record := range globalTracker.Installations {
-		if shouldIncludeRecord(record, listFilter) {
-			key := fmt.Sprintf("%s-%s-%s-%s-global", record.Target, record.Rule, record.Mode, record.FilePath)
-			if existing, exists := uniqueMap[key]; !exists || record.InstalledAt.After(existing.InstalledAt) {
-				uniqueMap[key] = uniqueInstall{
-					Target:      record.Target,
-					Rule:        record.Rule,
-					Mode:        record.Mode,
-					FilePath:    record.FilePath,
-					Global:      true,
-					InstalledAt: record.InstalledAt,
-				}
-			}
+	for _, record := range tracker.Installations {
+		if !shouldIncludeRecord(record, listFilter) {
+			continue
 		}
-	}
 
-	// Process project installations
-	if projectTracker != nil {
-		for _, record := range projectTracker.Installations {
-			if shouldIncludeRecord(record, listFilter) {
-				key := fmt.Sprintf(
-					"%s-%s-%s-%s-%s",
-					record.Target,
-					record.Rule,
-					record.Mode,
-					record.FilePath,
-					record.ProjectPath,
-				)
-				if existing, exists := uniqueMap[key]; !exists || record.InstalledAt.After(existing.InstalledAt) {
-					uniqueMap[key] = uniqueInstall{
-						Target:      record.Target,
-						Rule:        record.Rule,
-						Mode:        record.Mode,
-						FilePath:    record.FilePath,
-						Global:      false,
-						ProjectPath: record.ProjectPath,
-						InstalledAt: record.InstalledAt,
-					}
-				}
+		scope := "global"
+		if !record.Global {
+			scope = record.ProjectPath
+		}
+		key := fmt.Sprintf("%s-%s-%s-%s-%s", record.Target, record.Rule, record.Mode, record.FilePath, scope)
+		if existing, exists := uniqueMap[key]; !exists || record.InstalledAt.After(existing.InstalledAt) {
+			uniqueMap[key] = uniqueInstall{
+				Target:      record.Target,
+				Rule:        record.Rule,
+				Mode:        record.Mode,
+				FilePath:    record.FilePath,
+				Global:      record.Global,
+				ProjectPath: record.ProjectPath,
+				InstalledAt: record.InstalledAt,
+				Provenance:  record.Provenance,
 			}
 		}
 	}
@@ -1428,6 +1445,7 @@ func runListInstalled() error {
 	for _, install := range uniqueMap {
 		allInstalls = append(allInstalls, install)
 	}
+	recordInstallations(allInstalls)
 
 	// Check if no templates are installed
 	if len(allInstalls) == 0 {
@@ -1530,12 +1548,13 @@ type uniqueInstall struct {
 	Global      bool
 	ProjectPath string
 	InstalledAt time.Time
+	Provenance  config.Provenance
 }
 
 func displayTable(installs []uniqueInstall) {
 	// Print table header with wider columns
-	fmt.Printf("%-8s %-20s %-8s %-25s %-15s\n", "Target", "Rule", "Mode", "File", "Installed")
-	fmt.Println(strings.Repeat("-", 78))
+	fmt.Printf("%-8s %-20s %-8s %-25s %-20s %-15s\n", "Target", "Rule", "Mode", "File", "Source", "Installed")
+	fmt.Println(strings.Repeat("-", 99))
 
 	// Print each row
 	for _, install := range installs {
@@ -1567,7 +1586,12 @@ func displayTable(installs []uniqueInstall) {
 			fileName = fileName[:22] + "..."
 		}
 
-		fmt.Printf("%-8s %-20s %-8s %-25s %-15s\n", target, rule, mode, fileName, timeAgo)
+		source := formatProvenanceSource(install.Provenance)
+		if len(source) > 20 {
+			source = source[:17] + "..."
+		}
+
+		fmt.Printf("%-8s %-20s %-8s %-25s %-20s %-15s\n", target, rule, mode, fileName, source, timeAgo)
 	}
 }
 
@@ -1638,7 +1662,11 @@ func uninstallRules() error {
 
 		if !uninstallForce {
 			// Default mode: Show files and confirm
-			if !showUninstallPreviewAndConfirm(selectedInstallations) {
+			proceed, err := showUninstallPreviewAndConfirm(selectedInstallations)
+			if err != nil {
+				return err
+			}
+			if !proceed {
 				fmt.Println("Uninstallation cancelled")
 				return nil
 			}
@@ -1658,7 +1686,7 @@ func loadInstallations() ([]config.InstallationRecord, error) {
 	var allInstallations []config.InstallationRecord
 
 	// Load installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load installation tracker: %w", err)
 	}
@@ -1752,6 +1780,9 @@ func runInteractiveSelection(installations []config.InstallationRecord) ([]confi
 	}
 
 	// Run interactive selection
+	if err := requireInteractive("interactive uninstallation"); err != nil {
+		return nil, err
+	}
 	selectedItems, cancelled, err := ui.RunInteractiveSelection(config)
 	if err != nil {
 		return nil, fmt.Errorf("interactive selection failed: %w", err)
@@ -1828,23 +1859,15 @@ func prepareSelectionItems(installations []config.InstallationRecord) []selectio
 	return items
 }
 
-func showUninstallPreviewAndConfirm(installations []config.InstallationRecord) bool {
+func showUninstallPreviewAndConfirm(installations []config.InstallationRecord) (bool, error) {
 	// Display what will be uninstalled using table format
 	fmt.Println("🗑️  Files to be deleted:")
 	fmt.Println()
 	displayUninstallTable(installations)
 
-	// Ask for confirmation
-	fmt.Print("\nProceed with uninstallation? [y/N]: ")
-	var response string
-	if _, err := fmt.Scanln(&response); err != nil {
-		// If input fails, default to cancel for safety
-		fmt.Println("\nInput error, uninstallation cancelled")
-		return false
-	}
-
-	response = strings.ToLower(strings.TrimSpace(response))
-	return response == "y" || response == "yes"
+	// Ask for confirmation; without input nothing is deleted
+	fmt.Println()
+	return prompts.Confirm("Proceed with uninstallation?")
 }
 
 func displayUninstallTable(installations []config.InstallationRecord) {
@@ -1910,8 +1933,8 @@ func displayUninstallTable(installations []config.InstallationRecord) {
 
 func displayUninstallTableSection(installations []config.InstallationRecord) {
 	// Print table header
-	fmt.Printf("%-8s %-20s %-8s %-25s %-15s\n", "Target", "Rule", "Mode", "File", "Installed")
-	fmt.Println(strings.Repeat("-", 78))
+	fmt.Printf("%-8s %-20s %-8s %-25s %-20s %-15s\n", "Target", "Rule", "Mode", "File", "Source", "Installed")
+	fmt.Println(strings.Repeat("-", 99))
 
 	// Print each row
 	for _, install := range installations {
@@ -1937,17 +1960,16 @@ func displayUninstallTableSection(installations []config.InstallationRecord) {
 			fileName = fileName[:22] + "..."
 		}
 
-		fmt.Printf("%-8s %-20s %-8s %-25s %-15s\n", target, rule, mode, fileName, timeAgo)
+		source := formatProvenanceSource(install.Provenance)
+		if len(source) > 20 {
+			source = source[:17] + "..."
+		}
+
+		fmt.Printf("%-8s %-20s %-8s %-25s %-20s %-15s\n", target, rule, mode, fileName, source, timeAgo)
 	}
 }
 
 func performUninstallation(installations []config.InstallationRecord) error {
-	// Load tracker for removal
-	tracker, err := config.LoadGlobalInstallationTracker()
-	if err != nil {
-		return fmt.Errorf("failed to load installation tracker: %w", err)
-	}
-
 	if !uninstallForce && !uninstallInteractive {
 		fmt.Println()
 	}
@@ -1955,21 +1977,26 @@ func performUninstallation(installations []config.InstallationRecord) error {
 	uninstalled := 0
 	failed := 0
 
-	for _, installation := range installations {
-		if err := uninstallSingle(installation, tracker); err != nil {
-			fmt.Printf("  ⚠️  Failed to uninstall %s %s: %v\n", installation.Target, installation.Rule, err)
-			failed++
-		} else {
-			fmt.Printf("  ✅ Uninstalled %s %s (%s)\n", installation.Target, installation.Rule, installation.Mode)
-			uninstalled++
+	// Remove files and tracking records within one transaction per owning tracker
+	groups := groupInstallationsByTracker(installations)
+	for projectPath, group := range groups {
+		err := updateTrackerFor(projectPath == "", projectPath, func(tracker *config.InstallationTracker) error {
+			for _, installation := range group {
+				if err := uninstallSingle(installation, tracker); err != nil {
+					fmt.Printf("  ⚠️  Failed to uninstall %s %s: %v\n", installation.Target, installation.Rule, err)
+					failed++
+				} else {
+					fmt.Printf("  ✅ Uninstalled %s %s (%s)\n", installation.Target, installation.Rule, installation.Mode)
+					uninstalled++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to save installation tracker: %v\n", err)
 		}
 	}
 
-	// Save the updated tracker
-	if err := config.SaveGlobalInstallationTracker(tracker); err != nil {
-		fmt.Printf("Warning: failed to save installation tracker: %v\n", err)
-	}
-
 	fmt.Printf("\n🎉 Uninstalled %d installations", uninstalled)
 	if failed > 0 {
 		fmt.Printf(", %d failed", failed)
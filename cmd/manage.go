@@ -39,8 +39,13 @@ Examples:
   airuler manage uninstall          # Interactive uninstallation
   airuler manage uninstall --all    # Uninstall all installations without prompts
   airuler manage --clean            # Clean and rebuild everything`,
-	Args: cobra.MaximumNArgs(1),
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
 	RunE: func(_ *cobra.Command, args []string) error {
+		if jsonOutput() && (manageClean || len(args) == 0 || args[0] != "installations") {
+			return fmt.Errorf("--output json is only supported by 'airuler manage installations'")
+		}
+
 		if manageClean {
 			return runManageClean()
 		}
@@ -66,7 +71,7 @@ func runManage(subcommand string) error {
 	case "vendors":
 		return runManageVendors()
 	case "installations":
-		return runManageInstallations()
+		return runWithResult("manage installations", runManageInstallations)
 	case "uninstall":
 		return runManageUninstall()
 	case "":
@@ -218,14 +223,11 @@ func runManageClean() error {
 	fmt.Println()
 
 	// Ask for confirmation
-	fmt.Print("Continue with clean and rebuild? [y/N]: ")
-	var response string
-	if _, err := fmt.Scanln(&response); err != nil {
-		fmt.Println("Operation cancelled")
+	proceed, err := prompts.Confirm("Continue with clean and rebuild?")
+	if err != nil {
 		return err
 	}
-
-	if response != "y" && response != "Y" && response != "yes" {
+	if !proceed {
 		fmt.Println("Operation cancelled")
 		return nil
 	}
@@ -294,22 +296,21 @@ func showProjectStatus() error {
 		// Check for compiled rules
 		if _, err := os.Stat("compiled"); err == nil {
 			compiledDirs, err := os.ReadDir("compiled")
-			if err == nil {
-				fmt.Printf("  ⚙️  Compiled: %d target(s)\n", len(compiledDirs))
-			}
-		}
-
-		// Check for vendors
-		if _, err := os.Stat("vendors"); err == nil {
-			vendorDirs, err := os.ReadDir("vendors")
 			if err == nil {
 				count := 0
-				for _, dir := range vendorDirs {
+				for _, dir := range compiledDirs {
 					if dir.IsDir() {
 						count++
 					}
 				}
-				fmt.Printf("  📦 Vendors: %d vendor(s)\n", count)
+				fmt.Printf("  ⚙️  Compiled: %d target(s)\n", count)
+			}
+		}
+
+		// Check for vendors
+		if _, err := os.Stat("vendors"); err == nil {
+			if vendors, err := config.GetAvailableVendors("."); err == nil {
+				fmt.Printf("  📦 Vendors: %d vendor(s)\n", len(vendors))
 			}
 		}
 	} else {
@@ -322,7 +323,7 @@ func showProjectStatus() error {
 
 func showInstallationSummary() error {
 	// Load installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers()
 	if err != nil {
 		return err
 	}
@@ -360,7 +361,7 @@ func showInstallationSummary() error {
 
 func runUninstallAll() error {
 	// Load installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	tracker, err := loadInstallationTrackers()
 	if err != nil {
 		return fmt.Errorf("failed to load installation tracker: %w", err)
 	}
@@ -376,14 +377,12 @@ func runUninstallAll() error {
 
 	// Ask for confirmation unless --force is used
 	if !uninstallForce {
-		fmt.Printf("\nThis will uninstall ALL %d installation(s). Continue? [y/N]: ", len(installations))
-		var response string
-		if _, err := fmt.Scanln(&response); err != nil {
-			fmt.Println("Operation cancelled")
+		fmt.Println()
+		proceed, err := prompts.Confirm(fmt.Sprintf("This will uninstall ALL %d installation(s). Continue?", len(installations)))
+		if err != nil {
 			return err
 		}
-
-		if response != "y" && response != "Y" && response != "yes" {
+		if !proceed {
 			fmt.Println("Operation cancelled")
 			return nil
 		}
@@ -399,25 +398,28 @@ func runUninstallAll() error {
 	uninstallProject = false
 	uninstallForce = true // Skip individual confirmations
 
-	// Uninstall everything
+	// Uninstall everything within one transaction per owning tracker
 	removed := 0
 	failed := 0
 
-	for _, installation := range installations {
-		if err := uninstallSingle(installation, tracker); err != nil {
-			fmt.Printf("    ⚠️  Failed to uninstall %s %s: %v\n", installation.Target, installation.Rule, err)
-			failed++
-		} else {
-			fmt.Printf("    ✅ Uninstalled %s %s\n", installation.Target, installation.Rule)
-			removed++
+	for projectPath, group := range groupInstallationsByTracker(installations) {
+		err := updateTrackerFor(projectPath == "", projectPath, func(tracker *config.InstallationTracker) error {
+			for _, installation := range group {
+				if err := uninstallSingle(installation, tracker); err != nil {
+					fmt.Printf("    ⚠️  Failed to uninstall %s %s: %v\n", installation.Target, installation.Rule, err)
+					failed++
+				} else {
+					fmt.Printf("    ✅ Uninstalled %s %s\n", installation.Target, installation.Rule)
+					removed++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to save installation tracker: %v\n", err)
 		}
 	}
 
-	// Save the updated tracker
-	if err := config.SaveGlobalInstallationTracker(tracker); err != nil {
-		fmt.Printf("Warning: failed to save installation tracker: %v\n", err)
-	}
-
 	// Summary
 	fmt.Println()
 	if removed > 0 {
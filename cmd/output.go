@@ -0,0 +1,375 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/vendor"
+	"github.com/spf13/cobra"
+)
+
+// Output formats selected with --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// jsonOutputAnnotation marks the commands that support --output json
+const jsonOutputAnnotation = "airuler/json-output"
+
+// Rule statuses reported in results
+const (
+	ruleCompiled  = "compiled"
+	ruleSkipped   = "skipped"
+	ruleInstalled = "installed"
+	ruleUpdated   = "updated"
+	ruleUnchanged = "unchanged"
+	ruleFailed    = "failed"
+)
+
+var (
+	outputFormat string
+
+	// resultOutput receives the JSON result; human-readable output goes to stderr in JSON mode
+	resultOutput *os.File
+
+	// activeResult collects the results of the running command, nil outside runWithResult
+	activeResult *commandResult
+)
+
+// commandResult is the structured result of a command, written as JSON with --output json
+type commandResult struct {
+	Command       string               `json:"command"`
+	Success       bool                 `json:"success"`
+	Error         string               `json:"error,omitempty"`
+	StartedAt     time.Time            `json:"started_at"`
+	DurationMS    int64                `json:"duration_ms"`
+	Summary       map[string]int       `json:"summary,omitempty"` // number of rules by status
+	Steps         []stepResult         `json:"steps,omitempty"`
+	Vendors       []vendorResult       `json:"vendors,omitempty"`
+	Installations []installationResult `json:"installations,omitempty"`
+}
+
+// stepResult is one step of a workflow such as the compile step of deploy
+type stepResult struct {
+	Name       string       `json:"name"`
+	Success    bool         `json:"success"`
+	Error      string       `json:"error,omitempty"`
+	DurationMS int64        `json:"duration_ms"`
+	Files      []string     `json:"files,omitempty"` // files the step changed, e.g. by pulling
+	Rules      []ruleResult `json:"rules,omitempty"`
+}
+
+// ruleResult is the outcome of compiling or installing one rule for one target
+type ruleResult struct {
+	Target  string `json:"target"`
+	Rule    string `json:"rule,omitempty"`
+	Status  string `json:"status"`
+	Mode    string `json:"mode,omitempty"`
+	Source  string `json:"source,omitempty"`  // "local" or the vendor the template came from
+	Path    string `json:"path,omitempty"`    // compiled or installed file
+	Project string `json:"project,omitempty"` // project of a project installation
+	Error   string `json:"error,omitempty"`
+}
+
+// vendorResult describes a vendor from the lock file and, when checked, its update status
+type vendorResult struct {
+	Name        string     `json:"name"`
+	URL         string     `json:"url,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	Path        string     `json:"path,omitempty"`
+	Ref         string     `json:"ref,omitempty"`
+	Resolved    string     `json:"resolved,omitempty"`
+	Commit      string     `json:"commit,omitempty"`
+	SHA256      string     `json:"sha256,omitempty"`
+	FetchedAt   *time.Time `json:"fetched_at,omitempty"`
+	Indirect    bool       `json:"indirect,omitempty"`
+	Requires    []string   `json:"requires,omitempty"`
+	RequiredBy  []string   `json:"required_by,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Version     string     `json:"version,omitempty"`
+	Status      string     `json:"status,omitempty"` // up-to-date, update-available, missing or error
+	Latest      string     `json:"latest,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// installationResult is one tracked installation
+type installationResult struct {
+	Target      string    `json:"target"`
+	Rule        string    `json:"rule"`
+	Mode        string    `json:"mode,omitempty"`
+	Scope       string    `json:"scope"` // global or project
+	Project     string    `json:"project,omitempty"`
+	Path        string    `json:"path"`
+	Missing     bool      `json:"missing,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	Source      string    `json:"source,omitempty"`
+}
+
+// initOutput sends human-readable output to stderr when the result is written as JSON
+func initOutput() {
+	if outputFormat != outputJSON || resultOutput != nil {
+		return
+	}
+	resultOutput = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// checkOutputFormat validates --output for the command about to run
+func checkOutputFormat(cmd *cobra.Command) error {
+	switch outputFormat {
+	case outputText:
+		return nil
+	case outputJSON:
+		if cmd.Annotations[jsonOutputAnnotation] == "" {
+			return fmt.Errorf("--output json is not supported by '%s'", cmd.CommandPath())
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (use %s or %s)", outputFormat, outputText, outputJSON)
+}
+
+// jsonOutput returns whether results are written as JSON
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// runWithResult runs a command while collecting its results. With --output json the result
+// is written to stdout when the command ends, whether or not it succeeded.
+func runWithResult(command string, run func() error) error {
+	result := &commandResult{Command: command, StartedAt: time.Now()}
+	activeResult = result
+	defer func() { activeResult = nil }()
+
+	err := run()
+
+	result.DurationMS = time.Since(result.StartedAt).Milliseconds()
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	for _, step := range result.Steps {
+		for _, rule := range step.Rules {
+			if result.Summary == nil {
+				result.Summary = make(map[string]int)
+			}
+			result.Summary[rule.Status]++
+		}
+	}
+	if jsonOutput() {
+		if writeErr := writeResult(result); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+// writeResult writes a command result as indented JSON
+func writeResult(result *commandResult) error {
+	out := resultOutput
+	if out == nil {
+		out = os.Stdout
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
+
+// runStep runs one step of a workflow, recording its outcome and timing. Rules recorded
+// while it runs belong to the step; steps do not nest.
+func runStep(name string, run func() error) error {
+	if activeResult == nil {
+		return run()
+	}
+
+	activeResult.Steps = append(activeResult.Steps, stepResult{Name: name})
+	start := time.Now()
+	err := run()
+
+	step := &activeResult.Steps[len(activeResult.Steps)-1]
+	step.DurationMS = time.Since(start).Milliseconds()
+	step.Success = err == nil
+	if err != nil {
+		step.Error = err.Error()
+	}
+	sort.SliceStable(step.Rules, func(i, j int) bool {
+		if step.Rules[i].Target != step.Rules[j].Target {
+			return step.Rules[i].Target < step.Rules[j].Target
+		}
+		return step.Rules[i].Rule < step.Rules[j].Rule
+	})
+	return err
+}
+
+// currentStep returns the step being run, starting one named after the command if needed
+func currentStep() *stepResult {
+	if len(activeResult.Steps) == 0 {
+		activeResult.Steps = append(activeResult.Steps, stepResult{Name: activeResult.Command, Success: true})
+	}
+	return &activeResult.Steps[len(activeResult.Steps)-1]
+}
+
+// recordRule records the outcome of a rule in the current step
+func recordRule(rule ruleResult) {
+	if activeResult == nil {
+		return
+	}
+	step := currentStep()
+	step.Rules = append(step.Rules, rule)
+}
+
+// recordRuleFailure records a rule that failed with err
+func recordRuleFailure(rule ruleResult, err error) {
+	rule.Status = ruleFailed
+	rule.Error = errorString(err)
+	recordRule(rule)
+}
+
+// recordInstallationUpdate records the outcome of updating an existing installation
+func recordInstallationUpdate(installation config.InstallationRecord, status string, err error) {
+	result := ruleResult{
+		Target:  installation.Target,
+		Rule:    installation.Rule,
+		Status:  status,
+		Mode:    installation.Mode,
+		Source:  installation.Provenance.Source,
+		Path:    installation.FilePath,
+		Project: installation.ProjectPath,
+	}
+	if err != nil {
+		recordRuleFailure(result, err)
+		return
+	}
+	recordRule(result)
+}
+
+// recordFiles records files changed by the current step
+func recordFiles(files []string) {
+	if activeResult == nil || len(files) == 0 {
+		return
+	}
+	step := currentStep()
+	step.Files = append(step.Files, files...)
+}
+
+// errorString returns the message of an error, or "" for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// recordVendor records a vendor from the lock file with the manifest of its configuration, if known
+func recordVendor(name string, lock config.VendorLock, requiredBy []string, vendorConfigs *config.MergedVendorConfigs) {
+	if activeResult == nil {
+		return
+	}
+
+	result := vendorResult{
+		Name:       name,
+		URL:        lock.URL,
+		Source:     lock.Source,
+		Path:       lock.Path,
+		Ref:        lock.Ref,
+		Resolved:   lock.Resolved,
+		Commit:     lock.Commit,
+		SHA256:     lock.SHA256,
+		Indirect:   lock.Indirect,
+		Requires:   lock.Requires,
+		RequiredBy: requiredBy,
+	}
+	if !lock.FetchedAt.IsZero() {
+		fetchedAt := lock.FetchedAt
+		result.FetchedAt = &fetchedAt
+	}
+	if vendorConfigs != nil {
+		if vendorConfig, exists := vendorConfigs.VendorConfigs[name]; exists {
+			result.Description = vendorConfig.Vendor.Description
+			result.Version = vendorConfig.Vendor.Version
+		}
+	}
+	activeResult.Vendors = append(activeResult.Vendors, result)
+}
+
+// recordVendorStatus adds the update status of a checked vendor to its recorded entry
+func recordVendorStatus(status vendor.VendorStatus) {
+	if activeResult == nil {
+		return
+	}
+
+	var result *vendorResult
+	for i := range activeResult.Vendors {
+		if activeResult.Vendors[i].Name == status.Name {
+			result = &activeResult.Vendors[i]
+		}
+	}
+	if result == nil {
+		activeResult.Vendors = append(activeResult.Vendors, vendorResult{Name: status.Name})
+		result = &activeResult.Vendors[len(activeResult.Vendors)-1]
+	}
+
+	switch {
+	case status.Err != nil:
+		result.Status = "error"
+		result.Error = status.Err.Error()
+	case status.Missing:
+		result.Status = "missing"
+	case status.UpdateAvailable:
+		result.Status = "update-available"
+		result.Latest = status.Latest.ID()
+	default:
+		result.Status = "up-to-date"
+		result.Latest = status.Latest.ID()
+	}
+}
+
+// recordInstallations records tracked installations
+func recordInstallations(installs []uniqueInstall) {
+	if activeResult == nil {
+		return
+	}
+
+	for _, install := range installs {
+		result := installationResult{
+			Target:      install.Target,
+			Rule:        install.Rule,
+			Mode:        install.Mode,
+			Scope:       "global",
+			Path:        install.FilePath,
+			InstalledAt: install.InstalledAt,
+			Source:      install.Provenance.Source,
+		}
+		if !install.Global {
+			result.Scope = "project"
+			result.Project = install.ProjectPath
+		}
+		if _, err := os.Stat(install.FilePath); os.IsNotExist(err) {
+			result.Missing = true
+		}
+		activeResult.Installations = append(activeResult.Installations, result)
+	}
+	sort.SliceStable(activeResult.Installations, func(i, j int) bool {
+		a, b := activeResult.Installations[i], activeResult.Installations[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Rule < b.Rule
+	})
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// runJSON runs a command with --output json and decodes the result it writes
+func runJSON(t *testing.T, command string, run func() error) (commandResult, error) {
+	t.Helper()
+
+	originalFormat, originalOutput := outputFormat, resultOutput
+	t.Cleanup(func() { outputFormat, resultOutput = originalFormat, originalOutput })
+
+	out, err := os.CreateTemp(t.TempDir(), "result-*.json")
+	if err != nil {
+		t.Fatalf("Failed to create result file: %v", err)
+	}
+	defer out.Close()
+	outputFormat, resultOutput = outputJSON, out
+
+	var runErr error
+	captureOutput(func() { runErr = runWithResult(command, run) })
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatalf("Failed to read result: %v", err)
+	}
+	var result commandResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("result is not valid JSON: %v\n%s", err, data)
+	}
+	return result, runErr
+}
+
+func TestCheckOutputFormat(t *testing.T) {
+	originalFormat := outputFormat
+	t.Cleanup(func() { outputFormat = originalFormat })
+
+	supported := &cobra.Command{Use: "deploy", Annotations: map[string]string{jsonOutputAnnotation: "true"}}
+	unsupported := &cobra.Command{Use: "doctor"}
+
+	tests := []struct {
+		format  string
+		cmd     *cobra.Command
+		wantErr string
+	}{
+		{format: outputText, cmd: unsupported},
+		{format: outputJSON, cmd: supported},
+		{format: outputJSON, cmd: unsupported, wantErr: "--output json is not supported by 'doctor'"},
+		{format: "yaml", cmd: supported, wantErr: `unknown output format "yaml"`},
+	}
+
+	for _, tt := range tests {
+		outputFormat = tt.format
+		err := checkOutputFormat(tt.cmd)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("checkOutputFormat(%s, %s) error = %v", tt.format, tt.cmd.Use, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("checkOutputFormat(%s, %s) error = %v, want %q", tt.format, tt.cmd.Use, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunWithResult(t *testing.T) {
+	result, err := runJSON(t, "sync", func() error {
+		if err := runStep("compile", func() error {
+			recordRule(ruleResult{Target: "cursor", Rule: "b", Status: ruleCompiled})
+			recordRule(ruleResult{Target: "claude", Rule: "a", Status: ruleCompiled})
+			return nil
+		}); err != nil {
+			return err
+		}
+		return runStep("deploy", func() error {
+			recordRuleFailure(ruleResult{Target: "claude", Rule: "a"}, errors.New("disk full"))
+			return errors.New("deployment failed")
+		})
+	})
+
+	// The command still fails, so the exit code is non-zero
+	if err == nil || err.Error() != "deployment failed" {
+		t.Errorf("runWithResult() error = %v", err)
+	}
+	if result.Command != "sync" || result.Success || result.Error != "deployment failed" {
+		t.Errorf("result = %+v", result)
+	}
+	if want := map[string]int{ruleCompiled: 2, ruleFailed: 1}; !reflect.DeepEqual(result.Summary, want) {
+		t.Errorf("summary = %v, want %v", result.Summary, want)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("steps = %+v, want compile and deploy", result.Steps)
+	}
+
+	compile, deploy := result.Steps[0], result.Steps[1]
+	if compile.Name != "compile" || !compile.Success || compile.Rules[0].Target != "claude" {
+		t.Errorf("compile step = %+v, want success with rules sorted by target", compile)
+	}
+	if deploy.Success || deploy.Error != "deployment failed" || deploy.Rules[0].Error != "disk full" {
+		t.Errorf("deploy step = %+v", deploy)
+	}
+	if activeResult != nil {
+		t.Error("the result collector should be reset after the command")
+	}
+}
+
+func TestDeployJSONOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	projectDir := t.TempDir()
+	originalProject := deployProject
+	deployProject = projectDir
+	t.Cleanup(func() { deployProject = originalProject })
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("templates", "greet.tmpl"), []byte("Hello"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	deploy := func() commandResult {
+		t.Helper()
+		result, err := runJSON(t, "deploy", func() error { return runDeploy("cursor", "") })
+		if err != nil {
+			t.Fatalf("runDeploy() error = %v", err)
+		}
+		return result
+	}
+
+	installPath := filepath.Join(projectDir, ".cursor", "rules", "greet.mdc")
+	for _, wantStatus := range []string{ruleInstalled, ruleUnchanged} {
+		result := deploy()
+		if len(result.Steps) != 2 || result.Steps[0].Name != "compile" || result.Steps[1].Name != "install" {
+			t.Fatalf("steps = %+v, want compile and install", result.Steps)
+		}
+
+		compiled := result.Steps[0].Rules
+		if len(compiled) != 1 || compiled[0].Status != ruleCompiled || compiled[0].Source != "local" {
+			t.Errorf("compile rules = %+v", compiled)
+		}
+		want := []ruleResult{{Target: "cursor", Rule: "greet", Status: wantStatus, Path: installPath, Project: projectDir}}
+		if !reflect.DeepEqual(result.Steps[1].Rules, want) {
+			t.Errorf("install rules = %+v, want %+v", result.Steps[1].Rules, want)
+		}
+	}
+}
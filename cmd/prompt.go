@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes returned by airuler
+const (
+	exitOK                 = 0
+	exitError              = 1
+	exitConfirmationNeeded = 2 // a prompt could not be answered without input
+	exitDrift              = 3 // sync --check found installed files that are out of date
+)
+
+// errConfirmationNeeded is returned when a prompt needs an answer but input is disabled or
+// stdin is not a terminal. Commands fail with exitConfirmationNeeded.
+var errConfirmationNeeded = errors.New("confirmation needed")
+
+var (
+	assumeYes bool
+	noInput   bool
+)
+
+// prompter asks the user for confirmations and values. Every prompt goes through it, so
+// --yes and --no-input apply everywhere and tests can answer prompts.
+type prompter interface {
+	// Confirm asks a yes/no question that defaults to no
+	Confirm(question string) (bool, error)
+	// Ask asks for a value, returning defaultValue when nothing is entered. Without a
+	// default, empty answers are asked again.
+	Ask(question, defaultValue string) (string, error)
+}
+
+// prompts is the prompter used by all commands
+var prompts prompter = newTerminalPrompter(os.Stdin)
+
+// terminalPrompter reads answers from stdin, but only when it is a terminal, so that
+// scripts and CI runs fail instead of blocking or reading EOF as an answer
+type terminalPrompter struct {
+	in       *bufio.Reader
+	terminal bool
+}
+
+// newTerminalPrompter returns a prompter reading from in
+func newTerminalPrompter(in *os.File) *terminalPrompter {
+	return &terminalPrompter{in: bufio.NewReader(in), terminal: isInteractive(in)}
+}
+
+// Confirm asks a yes/no question; --yes answers it without asking
+func (p *terminalPrompter) Confirm(question string) (bool, error) {
+	if assumeYes {
+		fmt.Printf("%s [y/N]: y (--yes)\n", question)
+		return true, nil
+	}
+
+	answer, err := p.read(question, question+" [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// Ask asks for a value; without input the default is used if there is one
+func (p *terminalPrompter) Ask(question, defaultValue string) (string, error) {
+	label := question + ": "
+	if defaultValue != "" {
+		label = fmt.Sprintf("%s [%s]: ", question, defaultValue)
+	}
+	if assumeYes && defaultValue != "" {
+		fmt.Println(label + defaultValue + " (--yes)")
+		return defaultValue, nil
+	}
+
+	for {
+		answer, err := p.read(question, label)
+		if err != nil {
+			if defaultValue != "" {
+				return defaultValue, nil
+			}
+			return "", err
+		}
+		switch {
+		case answer != "":
+			return answer, nil
+		case defaultValue != "":
+			return defaultValue, nil
+		}
+		fmt.Println("This field is required. Please enter a value.")
+	}
+}
+
+// read prints a prompt and reads one line of input
+func (p *terminalPrompter) read(question, prompt string) (string, error) {
+	reason := inputUnavailable(p.terminal)
+	if reason == "" {
+		fmt.Print(prompt)
+		line, err := p.in.ReadString('\n')
+		if err == nil || (err == io.EOF && line != "") {
+			return strings.TrimSpace(line), nil
+		}
+		fmt.Println()
+		reason = "stdin was closed"
+	}
+	return "", fmt.Errorf("%w: %s (%s; rerun with --yes to confirm)",
+		errConfirmationNeeded, strings.TrimSpace(question), reason)
+}
+
+// inputUnavailable explains why prompts cannot be answered, or returns "" when they can
+func inputUnavailable(terminal bool) string {
+	switch {
+	case noInput:
+		return "--no-input is set"
+	case !terminal:
+		return "stdin is not a terminal"
+	}
+	return ""
+}
+
+// isInteractive reports whether in is a terminal a user can answer prompts on. CI runs are
+// never interactive.
+func isInteractive(in *os.File) bool {
+	if os.Getenv("CI") != "" {
+		return false
+	}
+	info, err := in.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// requireInteractive fails when an interactive selection cannot be shown
+func requireInteractive(action string) error {
+	if reason := inputUnavailable(isInteractive(os.Stdin)); reason != "" {
+		return fmt.Errorf("%w: %s needs an interactive terminal (%s)", errConfirmationNeeded, action, reason)
+	}
+	return nil
+}
+
+// exitCode returns the process exit code for the error a command failed with
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errConfirmationNeeded):
+		return exitConfirmationNeeded
+	case errors.Is(err, errDrift):
+		return exitDrift
+	}
+	return exitError
+}
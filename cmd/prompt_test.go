@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+// fakePrompter answers confirmations from a list and records the questions asked
+type fakePrompter struct {
+	answers []bool
+	err     error
+	asked   []string
+}
+
+func (p *fakePrompter) Confirm(question string) (bool, error) {
+	p.asked = append(p.asked, question)
+	if p.err != nil || len(p.answers) == 0 {
+		return false, p.err
+	}
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	return answer, nil
+}
+
+func (p *fakePrompter) Ask(question, defaultValue string) (string, error) {
+	p.asked = append(p.asked, question)
+	return defaultValue, p.err
+}
+
+// usePrompter replaces the prompter for the duration of a test
+func usePrompter(t *testing.T, p prompter) {
+	t.Helper()
+	original := prompts
+	prompts = p
+	t.Cleanup(func() { prompts = original })
+}
+
+func TestTerminalPrompter(t *testing.T) {
+	originalYes, originalNoInput := assumeYes, noInput
+	t.Cleanup(func() { assumeYes, noInput = originalYes, originalNoInput })
+
+	newPrompter := func(input string, terminal bool) *terminalPrompter {
+		return &terminalPrompter{in: bufio.NewReader(strings.NewReader(input)), terminal: terminal}
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		terminal  bool
+		yes       bool
+		noInput   bool
+		want      bool
+		wantErr   string
+		wantAsked bool
+	}{
+		{name: "yes", input: "y\n", terminal: true, want: true, wantAsked: true},
+		{name: "full word", input: " YES\n", terminal: true, want: true, wantAsked: true},
+		{name: "no", input: "n\n", terminal: true, wantAsked: true},
+		{name: "empty answer defaults to no", input: "\n", terminal: true, wantAsked: true},
+		{name: "closed stdin needs confirmation", terminal: true, wantErr: "stdin was closed", wantAsked: true},
+		{name: "not a terminal", input: "y\n", wantErr: "stdin is not a terminal"},
+		{name: "no input", input: "y\n", terminal: true, noInput: true, wantErr: "--no-input is set"},
+		{name: "assume yes", terminal: false, yes: true, want: true},
+		{name: "assume yes wins over no input", yes: true, noInput: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assumeYes, noInput = tt.yes, tt.noInput
+			p := newPrompter(tt.input, tt.terminal)
+
+			var got bool
+			var err error
+			output := captureOutput(func() { got, err = p.Confirm("Continue?") })
+
+			if tt.wantErr != "" {
+				if !errors.Is(err, errConfirmationNeeded) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Confirm() error = %v, want %q", err, tt.wantErr)
+				}
+				if exitCode(fmt.Errorf("sync failed: %w", err)) != exitConfirmationNeeded {
+					t.Errorf("exit code for %v is not %d", err, exitConfirmationNeeded)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("Confirm() = %v, %v; want %v", got, err, tt.want)
+			}
+			if asked := strings.Contains(output, "Continue? [y/N]: ") && !strings.Contains(output, "(--yes)"); asked != tt.wantAsked {
+				t.Errorf("prompt shown = %v, want %v (output %q)", asked, tt.wantAsked, output)
+			}
+		})
+	}
+
+	t.Run("ask", func(t *testing.T) {
+		assumeYes, noInput = false, false
+
+		var value string
+		var err error
+		captureOutput(func() { value, err = newPrompter("Jane\n", true).Ask("Name", "") })
+		if value != "Jane" || err != nil {
+			t.Errorf("Ask() = %q, %v; want the entered value", value, err)
+		}
+
+		captureOutput(func() { value, err = newPrompter("\n", true).Ask("Name", "Default") })
+		if value != "Default" || err != nil {
+			t.Errorf("Ask() = %q, %v; want the default for an empty answer", value, err)
+		}
+
+		output := captureOutput(func() { value, err = newPrompter("\n  \nJane\n", true).Ask("Name", "") })
+		if value != "Jane" || err != nil {
+			t.Errorf("Ask() = %q, %v; want empty answers asked again", value, err)
+		}
+		if got := strings.Count(output, "This field is required"); got != 2 {
+			t.Errorf("required notice shown %d times, want 2 (output %q)", got, output)
+		}
+
+		captureOutput(func() { _, err = newPrompter("\n", true).Ask("Name", "") })
+		if !errors.Is(err, errConfirmationNeeded) {
+			t.Errorf("Ask() after stdin closed error = %v", err)
+		}
+
+		captureOutput(func() { value, err = newPrompter("", false).Ask("Name", "Default") })
+		if value != "Default" || err != nil {
+			t.Errorf("Ask() = %q, %v; want the default without input", value, err)
+		}
+
+		captureOutput(func() { _, err = newPrompter("", false).Ask("Name", "") })
+		if !errors.Is(err, errConfirmationNeeded) {
+			t.Errorf("Ask() without input or default error = %v", err)
+		}
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: exitOK},
+		{err: errors.New("boom"), want: exitError},
+		{err: fmt.Errorf("git pull failed: %w", errConfirmationNeeded), want: exitConfirmationNeeded},
+		{err: fmt.Errorf("%w: 1 installed file(s) out of date", errDrift), want: exitDrift},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestUninstallAllConfirmation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	originalForce := uninstallForce
+	uninstallForce = false
+	t.Cleanup(func() { uninstallForce = originalForce })
+
+	installed := filepath.Join(t.TempDir(), "rule.md")
+	if err := os.WriteFile(installed, []byte("rule"), 0644); err != nil {
+		t.Fatalf("Failed to write installed file: %v", err)
+	}
+	err := config.UpdateGlobalInstallationTracker(func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(config.InstallationRecord{Target: "cline", Rule: "rule", Global: true, FilePath: installed})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to seed tracker: %v", err)
+	}
+
+	// Without an answer nothing is removed and the command fails with the confirmation exit code
+	fake := &fakePrompter{err: fmt.Errorf("%w: no terminal", errConfirmationNeeded)}
+	usePrompter(t, fake)
+	captureOutput(func() { err = runUninstallAll() })
+	if exitCode(err) != exitConfirmationNeeded {
+		t.Errorf("runUninstallAll() error = %v, want confirmation needed", err)
+	}
+	if len(fake.asked) != 1 || !strings.Contains(fake.asked[0], "uninstall ALL 1 installation(s)") {
+		t.Errorf("questions asked = %q", fake.asked)
+	}
+
+	// Declining cancels
+	usePrompter(t, &fakePrompter{answers: []bool{false}})
+	captureOutput(func() { err = runUninstallAll() })
+	if err != nil {
+		t.Errorf("runUninstallAll() error = %v", err)
+	}
+	if _, err := os.Stat(installed); err != nil {
+		t.Errorf("installed file should remain until confirmed: %v", err)
+	}
+
+	// Confirming removes the installation
+	usePrompter(t, &fakePrompter{answers: []bool{true}})
+	captureOutput(func() { err = runUninstallAll() })
+	if err != nil {
+		t.Errorf("runUninstallAll() error = %v", err)
+	}
+	if _, err := os.Stat(installed); !os.IsNotExist(err) {
+		t.Errorf("installed file should be removed after confirmation: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/git"
+)
+
+// getTemplateRepoCommit returns the HEAD commit of the template repository, or "" if it is not a git repository
+func getTemplateRepoCommit(templateDir string) string {
+	repo := git.NewGitRepository("", templateDir)
+	if !repo.Exists() {
+		return ""
+	}
+
+	commit, err := repo.GetCurrentCommit()
+	if err != nil {
+		return ""
+	}
+	return commit
+}
+
+// templateProvenance builds the provenance of a template from its source and the vendor lock file
+func templateProvenance(source TemplateSource, lockFile *config.LockFile, templateCommit string) config.Provenance {
+	provenance := config.Provenance{
+		Source:         source.SourceType,
+		TemplateCommit: templateCommit,
+		TemplateHash:   fmt.Sprintf("%x", sha256.Sum256([]byte(source.Content))),
+		AirulerVersion: version,
+	}
+
+	if source.SourceType != "local" {
+		if vendorLock, exists := lockFile.Vendors[source.SourceType]; exists {
+			provenance.VendorCommit = vendorLock.Version()
+		}
+	}
+
+	return provenance
+}
+
+// combineProvenance merges the provenance of templates that were combined into a single output file
+func combineProvenance(fileName string, files []config.CompiledFile) config.CompiledFile {
+	combined := config.CompiledFile{File: fileName}
+	if len(files) == 0 {
+		return combined
+	}
+
+	// Sort for a stable template list and hash regardless of map iteration order
+	sorted := append([]config.CompiledFile{}, files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Template < sorted[j].Template })
+
+	var templates, sources, commits []string
+	hash := sha256.New()
+	for _, file := range sorted {
+		templates = append(templates, file.Template)
+		if !slices.Contains(sources, file.Provenance.Source) {
+			sources = append(sources, file.Provenance.Source)
+		}
+		if file.Provenance.VendorCommit != "" {
+			commit := file.Provenance.Source + "@" + file.Provenance.VendorCommit
+			if !slices.Contains(commits, commit) {
+				commits = append(commits, commit)
+			}
+		}
+		hash.Write([]byte(file.Provenance.TemplateHash))
+	}
+
+	combined.Template = strings.Join(templates, ",")
+	combined.Provenance = config.Provenance{
+		Source:         strings.Join(sources, ","),
+		VendorCommit:   strings.Join(commits, ","),
+		TemplateCommit: sorted[0].Provenance.TemplateCommit,
+		TemplateHash:   fmt.Sprintf("%x", hash.Sum(nil)),
+		AirulerVersion: sorted[0].Provenance.AirulerVersion,
+	}
+	return combined
+}
+
+// lookupProvenance returns the provenance recorded for a compiled rule, if the compile manifest has it
+func lookupProvenance(target, rule string) config.Provenance {
+	manifest, err := config.LoadCompileManifest("compiled")
+	if err != nil {
+		return config.Provenance{}
+	}
+
+	file, found := manifest.Lookup(target, rule)
+	if !found {
+		return config.Provenance{}
+	}
+	return file.Provenance
+}
+
+// formatProvenanceSource renders the source of a rule as "local" or "vendor@shortcommit"
+func formatProvenanceSource(provenance config.Provenance) string {
+	if provenance.Source == "" {
+		return "-"
+	}
+	if provenance.VendorCommit == "" || strings.Contains(provenance.Source, ",") {
+		return provenance.Source
+	}
+	return provenance.Source + "@" + shortCommit(provenance.VendorCommit)
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
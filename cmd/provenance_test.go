@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+func TestTemplateProvenance(t *testing.T) {
+	lockFile := config.NewLockFile()
+	lockFile.Vendors["acme"] = config.VendorLock{URL: "https://example.com/acme.git", Commit: "0123456789abcdef"}
+
+	vendored := templateProvenance(TemplateSource{Content: "hello", SourceType: "acme"}, lockFile, "feedface")
+	if vendored.Source != "acme" || vendored.VendorCommit != "0123456789abcdef" || vendored.TemplateCommit != "feedface" {
+		t.Errorf("unexpected vendor provenance: %+v", vendored)
+	}
+	if len(vendored.TemplateHash) != 64 {
+		t.Errorf("TemplateHash = %q, want sha256 hex", vendored.TemplateHash)
+	}
+
+	local := templateProvenance(TemplateSource{Content: "hello", SourceType: "local"}, lockFile, "")
+	if local.VendorCommit != "" {
+		t.Errorf("local template should have no vendor commit, got %q", local.VendorCommit)
+	}
+	if local.TemplateHash != vendored.TemplateHash {
+		t.Error("identical content should produce identical template hashes")
+	}
+}
+
+func TestCombineProvenance(t *testing.T) {
+	files := []config.CompiledFile{
+		{Template: "b", Provenance: config.Provenance{Source: "acme", VendorCommit: "c1", TemplateHash: "h2"}},
+		{Template: "a", Provenance: config.Provenance{Source: "local", TemplateHash: "h1"}},
+		{Template: "c", Provenance: config.Provenance{Source: "acme", VendorCommit: "c1", TemplateHash: "h3"}},
+	}
+
+	combined := combineProvenance("CLAUDE.md", files)
+	if combined.File != "CLAUDE.md" || combined.Template != "a,b,c" {
+		t.Errorf("unexpected combined file: %+v", combined)
+	}
+	if combined.Provenance.Source != "local,acme" {
+		t.Errorf("Source = %q, want %q", combined.Provenance.Source, "local,acme")
+	}
+	if combined.Provenance.VendorCommit != "acme@c1" {
+		t.Errorf("VendorCommit = %q, want %q", combined.Provenance.VendorCommit, "acme@c1")
+	}
+
+	// Order of input must not affect the combined hash
+	reversed := []config.CompiledFile{files[2], files[1], files[0]}
+	if combineProvenance("CLAUDE.md", reversed).Provenance.TemplateHash != combined.Provenance.TemplateHash {
+		t.Error("combined hash depends on input order")
+	}
+}
+
+func TestFormatProvenanceSource(t *testing.T) {
+	tests := []struct {
+		provenance config.Provenance
+		want       string
+	}{
+		{config.Provenance{}, "-"},
+		{config.Provenance{Source: "local"}, "local"},
+		{config.Provenance{Source: "acme", VendorCommit: "0123456789abcdef"}, "acme@01234567"},
+		{config.Provenance{Source: "local,acme", VendorCommit: "acme@0123"}, "local,acme"},
+	}
+
+	for _, tt := range tests {
+		if got := formatProvenanceSource(tt.provenance); got != tt.want {
+			t.Errorf("formatProvenanceSource(%+v) = %q, want %q", tt.provenance, got, tt.want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/git"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -24,21 +25,29 @@ var rootCmd = &cobra.Command{
 for various AI coding assistants including Cursor, Claude Code, Cline, and GitHub Copilot.
 
 It supports template inheritance, vendor management, and multi-repository workflows.`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return checkOutputFormat(cmd)
+	},
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
+	// Output is set up first so that startup messages already go to stderr in JSON mode
+	cobra.OnInitialize(initOutput)
 	cobra.OnInitialize(initConfig)
 	cobra.OnInitialize(setupWorkingDirectory)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: project dir or ~/.config/airuler/airuler.yaml)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "output format: text or json")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "answer yes to all confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "never prompt; fail with exit code 2 when a confirmation is needed")
 	if err := viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")); err != nil {
 		// This should never happen with a valid flag, but handle it gracefully
 		panic(fmt.Sprintf("failed to bind verbose flag: %v", err))
@@ -68,6 +77,29 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil && viper.GetBool("verbose") {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
+
+	configureGitBackend()
+}
+
+// configureGitBackend selects the git backend from AIRULER_GIT_BACKEND or git.backend in the
+// project or global config
+func configureGitBackend() {
+	if backend := os.Getenv(git.BackendEnv); backend != "" {
+		if _, err := git.NewRepositoryFactory(backend); err != nil {
+			fmt.Printf("Warning: Ignoring %s: %v\n", git.BackendEnv, err)
+		}
+		return
+	}
+
+	backend := viper.GetString("git.backend")
+	if backend == "" {
+		if globalConfig, err := config.LoadGlobalConfig(); err == nil {
+			backend = globalConfig.Git.Backend
+		}
+	}
+	if err := git.SetDefaultBackend(backend); err != nil {
+		fmt.Printf("Warning: Ignoring git.backend: %v\n", err)
+	}
 }
 
 func setupWorkingDirectory() {
@@ -136,6 +168,11 @@ func shouldSkipDirectorySwitching() bool {
 
 	command := os.Args[1]
 
+	// config explain reads the project's templates, like compile
+	if command == "config" && len(os.Args) > 2 && os.Args[2] == "explain" {
+		return false
+	}
+
 	// Commands that should NOT auto-switch to last template directory
 	skipCommands := []string{
 		"init",      // Creates new projects, should respect current directory
@@ -78,6 +78,7 @@ func TestRootCommandSubcommands(t *testing.T) {
 		"vendors",
 		"watch",
 		"version",
+		"doctor",
 	}
 
 	commands := rootCmd.Commands()
@@ -4,13 +4,16 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
-	gogit "github.com/go-git/go-git/v5"
 	"github.com/ratler/airuler/internal/compiler"
 	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/git"
 	"github.com/ratler/airuler/internal/vendor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -24,7 +27,11 @@ var (
 	syncScope             string
 	syncTargets           string
 	syncDryRun            bool
+	syncDiff              bool
+	syncCheck             bool
+	syncProject           string
 	syncForce             bool
+	syncAllowDirty        bool
 	updateInstalledGlobal bool
 )
 
@@ -37,6 +44,24 @@ var syncCmd = &cobra.Command{
 3. Compile templates (unless --no-compile)  
 4. Update existing installations (unless --no-deploy)
 
+--dry-run changes nothing in the project. It fetches the template repository
+and lists the files a pull would bring in, lists the vendors that would move
+to a new commit, compiles the pulled templates and updated vendors in memory
+and compares them with compiled/, and lists the installed files that would be
+created, changed or left unchanged. Add --diff for a unified diff of the
+changes.
+
+--check is meant for CI. It compiles the templates with the locked vendors in
+memory, compares the result with the existing installations, such as a
+project's committed .cursor/rules or CLAUDE.md, and prints the differences.
+It writes nothing, never pulls or updates, and exits with status 3 when any
+installed file is out of date. Commit the project's .airuler/installs.yaml
+(see 'airuler deploy --track-in-project') so the check knows what is installed.
+
+Commands listed under hooks in airuler.yaml run before and after compiling
+and deploying (pre_compile, post_compile, pre_deploy, post_deploy). A hook
+that exits with a non-zero status aborts the sync.
+
 This replaces the common workflow: git pull → update → compile → update-installed
 
 Examples:
@@ -47,16 +72,29 @@ Examples:
   airuler sync --no-compile         # Skip compilation (pull → update vendors → deploy existing)
   airuler sync --no-deploy          # Skip deployment (pull → update vendors → compile only)
   airuler sync --scope project      # Sync only project installations
+                                    # (includes <project>/.airuler/installs.yaml of the current directory)
   airuler sync --targets cursor,claude  # Sync only specific targets
-  airuler sync --dry-run            # Show what would happen without doing it`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: func(_ *cobra.Command, args []string) error {
+  airuler sync --dry-run            # Show what would happen without doing it
+  airuler sync --dry-run --diff     # Also show a unified diff of every file that would change
+  airuler sync --check              # Fail with exit code 3 when installed files are out of date
+  airuler sync --check --project .  # Check only the installations of the project in .
+  airuler sync --allow-dirty        # Compile vendors even if they were modified locally
+
+Sync refuses to run when a vendor's files differ from the tree hash in
+airuler.lock; see 'airuler vendors verify'.`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var targetFilter string
 		if len(args) > 0 {
 			targetFilter = args[0]
 		}
 
-		return runSync(targetFilter)
+		if syncCheck {
+			// Check failures are results for CI, not usage mistakes
+			cmd.SilenceUsage = true
+		}
+		return runWithResult("sync", func() error { return runSync(targetFilter) })
 	},
 }
 
@@ -70,13 +108,23 @@ func init() {
 	syncCmd.Flags().StringVarP(&syncScope, "scope", "s", "all", "installation scope: global, project, or all")
 	syncCmd.Flags().StringVarP(&syncTargets, "targets", "t", "", "comma-separated list of targets (e.g., cursor,claude)")
 	syncCmd.Flags().BoolVarP(&syncDryRun, "dry-run", "n", false, "show what would happen without executing")
+	syncCmd.Flags().BoolVar(&syncDiff, "diff", false, "with --dry-run, show a unified diff of the files that would change")
+	syncCmd.Flags().BoolVar(&syncCheck, "check", false, "check that installations are up to date without writing; exit 3 on drift")
+	syncCmd.Flags().StringVar(&syncProject, "project", "", "only the installations of this project, including its .airuler/installs.yaml")
 	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "skip confirmation prompts")
+	syncCmd.Flags().BoolVar(&syncAllowDirty, "allow-dirty", false, "compile vendors that were modified locally")
 }
 
 func runSync(targetFilter string) error {
+	if syncCheck {
+		return runSyncCheck(targetFilter)
+	}
 	if syncDryRun {
 		return runSyncDryRun(targetFilter)
 	}
+	if syncDiff {
+		return fmt.Errorf("--diff requires --dry-run")
+	}
 
 	var steps []string
 	if !syncNoUpdate && !syncNoGitPull {
@@ -108,32 +156,88 @@ func runSync(targetFilter string) error {
 	}
 	fmt.Println()
 
+	// Vendors edited in place would be compiled and deployed silently
+	if !syncAllowDirty && (!syncNoUpdate || !syncNoCompile) {
+		manager, err := createVendorManager()
+		if err != nil {
+			return err
+		}
+		if err := checkVendorsClean(manager); err != nil {
+			return err
+		}
+	}
+
 	// Step 0: Git pull template repository
+	var pulledFiles []string
 	if !syncNoUpdate && !syncNoGitPull {
-		if err := runSyncGitPull(); err != nil {
+		err := runStep("pull", func() (err error) {
+			pulledFiles, err = runSyncGitPull()
+			recordFiles(pulledFiles)
+			return err
+		})
+		if err != nil {
 			return fmt.Errorf("git pull failed: %w", err)
 		}
 	}
 
 	// Step 1: Update vendors
 	if !syncNoUpdate {
-		if err := runSyncUpdateVendors(); err != nil {
+		if err := runStep("update_vendors", runSyncUpdateVendors); err != nil {
 			return fmt.Errorf("vendor update failed: %w", err)
 		}
 	}
 
+	// Hooks are read after the pull, which may have changed them
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	targets, err := syncTargetList(targetFilter)
+	if err != nil {
+		return err
+	}
+
 	// Step 2: Compile templates
+	var compiledFiles []string
 	if !syncNoCompile {
-		if err := runSyncCompile(targetFilter); err != nil {
+		if err := runHooks(cfg.Hooks, hookPreCompile, hookContext{targets: targets, changedFiles: pulledFiles}); err != nil {
+			return fmt.Errorf("sync aborted: %w", err)
+		}
+
+		before, err := snapshotFiles("compiled")
+		if err != nil {
+			return err
+		}
+		if err := runStep("compile", func() error { return runSyncCompile(targets) }); err != nil {
 			return fmt.Errorf("compilation failed: %w", err)
 		}
+		if compiledFiles, err = changedFilesSince("compiled", before); err != nil {
+			return err
+		}
+
+		if err := runHooks(cfg.Hooks, hookPostCompile, hookContext{targets: targets, changedFiles: compiledFiles}); err != nil {
+			return fmt.Errorf("sync aborted: %w", err)
+		}
 	}
 
 	// Step 3: Update installations
 	if !syncNoDeploy {
-		if err := runSyncDeploy(targetFilter); err != nil {
+		if err := runHooks(cfg.Hooks, hookPreDeploy, hookContext{targets: targets, changedFiles: compiledFiles}); err != nil {
+			return fmt.Errorf("sync aborted: %w", err)
+		}
+
+		var deployedFiles []string
+		err := runStep("deploy", func() (err error) {
+			deployedFiles, err = runSyncDeploy(targetFilter)
+			return err
+		})
+		if err != nil {
 			return fmt.Errorf("deployment failed: %w", err)
 		}
+
+		if err := runHooks(cfg.Hooks, hookPostDeploy, hookContext{targets: targets, changedFiles: deployedFiles}); err != nil {
+			return fmt.Errorf("sync aborted: %w", err)
+		}
 	}
 
 	fmt.Printf("\n🎉 Sync completed successfully\n")
@@ -146,6 +250,9 @@ func runSyncDryRun(targetFilter string) error {
 
 	// Show what steps would run
 	var steps []string
+	if !syncNoUpdate && !syncNoGitPull {
+		steps = append(steps, "🔄 Pull template repository")
+	}
 	if !syncNoUpdate {
 		steps = append(steps, "📥 Update vendor repositories")
 	}
@@ -178,34 +285,138 @@ func runSyncDryRun(targetFilter string) error {
 		fmt.Printf("🌍 Scope: %s\n", syncScope)
 	}
 
-	// Show current state
+	// Show the hooks of the stages that would run
+	var stages []string
+	if !syncNoCompile {
+		stages = append(stages, hookPreCompile, hookPostCompile)
+	}
+	if !syncNoDeploy {
+		stages = append(stages, hookPreDeploy, hookPostDeploy)
+	}
+	if cfg, err := loadProjectConfig(); err == nil {
+		printed := false
+		for _, stage := range stages {
+			for _, command := range hookCommands(cfg.Hooks, stage) {
+				if !printed {
+					fmt.Println("\n🪝 Hooks that would run:")
+					printed = true
+				}
+				fmt.Printf("  %s: %s\n", stage, command)
+			}
+		}
+	}
+
+	targets, err := syncTargetList(targetFilter)
+	if err != nil {
+		return err
+	}
+
+	var summary []string
+	var pull *pullPlan
+	if !syncNoUpdate && !syncNoGitPull {
+		fmt.Println("\n🔄 Template repository:")
+		if pull, err = planPull(git.DefaultGitRepositoryFactory()); err != nil {
+			fmt.Printf("  Warning: could not check for incoming changes: %v\n", err)
+		} else {
+			summary = append(summary, fmt.Sprintf("%d incoming file(s)", printPullPlan(pull)))
+		}
+	}
+
+	var manager *vendor.Manager
+	var statuses []vendor.VendorStatus
+	vendorUpdates := 0
 	if !syncNoUpdate {
-		fmt.Println("\n📥 Vendor repositories that would be updated:")
-		if err := showVendorStatus(); err != nil {
+		fmt.Println("\n📥 Vendor repositories:")
+		if manager, err = readVendorManager(); err != nil {
 			fmt.Printf("  Warning: could not check vendor status: %v\n", err)
+		} else {
+			statuses = manager.CheckStatus()
+			vendorUpdates = printVendorPlan(statuses)
+		}
+		summary = append(summary, fmt.Sprintf("%d vendor update(s)", vendorUpdates))
+	}
+
+	// Installations are updated from the freshly compiled rules, or from compiled/ without compiling
+	var rules map[string][]byte
+	if !syncNoCompile {
+		fmt.Println("\n⚙️  Compiled rules (compared with compiled/):")
+		if pull.pending() || vendorUpdates > 0 {
+			fmt.Println("  💡 Compiled with the pulled templates and updated vendors")
+		}
+		plan, err := planSyncedCompile(targets, pull, manager, statuses, vendorUpdates)
+		if err != nil {
+			fmt.Printf("  Warning: could not compile templates: %v\n", err)
+		} else {
+			if err := printCompilePlan(plan); err != nil {
+				return err
+			}
+			rules = plan.files
+			summary = append(summary, fmt.Sprintf("%d compiled file(s) changed", len(plan.changes)))
 		}
+	} else if rules, err = readCompiledRules(compiler.AllTargets); err != nil {
+		fmt.Printf("  Warning: could not read compiled rules: %v\n", err)
 	}
 
 	if !syncNoDeploy {
 		fmt.Println("\n🚀 Installations that would be updated:")
-		if err := showInstallationStatus(targetFilter); err != nil {
+		plans, err := planSyncInstallations(targetFilter, rules)
+		if err != nil {
 			fmt.Printf("  Warning: could not check installation status: %v\n", err)
+		} else {
+			counts, err := printInstallationPlans(plans, syncDiff)
+			if err != nil {
+				return err
+			}
+			summary = append(summary, fmt.Sprintf("%d installed file(s) created, %d changed, %d unchanged",
+				counts[planCreate], counts[planChange], counts[planUnchanged]))
 		}
 	}
 
+	if len(summary) > 0 {
+		fmt.Printf("\n📊 Plan: %s\n", strings.Join(summary, ", "))
+	}
 	fmt.Println("\n💡 Run without --dry-run to execute these changes")
 	return nil
 }
 
+// planSyncInstallations plans the update of every installation a sync would update, from
+// rules keyed by path relative to compiled/
+func planSyncInstallations(targetFilter string, rules map[string][]byte) ([]installationPlan, error) {
+	if rules == nil {
+		return nil, fmt.Errorf("no compiled rules to install")
+	}
+
+	tracker, err := readInstallationTrackers(syncProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load installation tracker: %w", err)
+	}
+	installations, err := selectSyncInstallations(tracker, targetFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(installations, func(a, b config.InstallationRecord) int {
+		return cmp.Or(
+			strings.Compare(a.Target, b.Target),
+			strings.Compare(a.Rule, b.Rule),
+			strings.Compare(a.ProjectPath, b.ProjectPath),
+		)
+	})
+
+	plans := make([]installationPlan, 0, len(installations))
+	for _, installation := range installations {
+		plans = append(plans, planInstallation(installation, rules))
+	}
+	return plans, nil
+}
+
 func runSyncUpdateVendors() error {
 	fmt.Println("📥 Updating vendor repositories...")
 
 	// Load config
-	cfg := config.NewDefaultConfig()
-	if viper.ConfigFileUsed() != "" {
-		if err := viper.Unmarshal(cfg); err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return err
 	}
 
 	// Create vendor manager
@@ -223,30 +434,45 @@ func runSyncUpdateVendors() error {
 	return nil
 }
 
-func runSyncCompile(targetFilter string) error {
-	fmt.Println("⚙️  Compiling templates...")
+// checkVendorsClean fails when a vendor was modified after it was fetched
+func checkVendorsClean(manager *vendor.Manager) error {
+	modified := manager.ModifiedVendors()
+	if len(modified) == 0 {
+		return nil
+	}
 
-	// Parse target filter
-	var targets []compiler.Target
+	return fmt.Errorf("vendor(s) modified locally: %s (run 'airuler vendors verify' for details, or use --allow-dirty)",
+		strings.Join(modified, ", "))
+}
+
+// syncTargetList returns the targets selected by the target argument or --targets, or all targets
+func syncTargetList(targetFilter string) ([]compiler.Target, error) {
 	if targetFilter != "" {
 		target := compiler.Target(targetFilter)
 		if !isValidTarget(target) {
-			return fmt.Errorf("invalid target: %s", targetFilter)
-		}
-		targets = []compiler.Target{target}
-	} else if syncTargets != "" {
-		targetNames := strings.Split(syncTargets, ",")
-		for _, name := range targetNames {
-			target := compiler.Target(strings.TrimSpace(name))
-			if !isValidTarget(target) {
-				return fmt.Errorf("invalid target: %s", target)
-			}
-			targets = append(targets, target)
+			return nil, fmt.Errorf("invalid target: %s", targetFilter)
 		}
-	} else {
-		targets = compiler.AllTargets
+		return []compiler.Target{target}, nil
+	}
+
+	if syncTargets == "" {
+		return compiler.AllTargets, nil
 	}
 
+	var targets []compiler.Target
+	for _, name := range strings.Split(syncTargets, ",") {
+		target := compiler.Target(strings.TrimSpace(name))
+		if !isValidTarget(target) {
+			return nil, fmt.Errorf("invalid target: %s", target)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+func runSyncCompile(targets []compiler.Target) error {
+	fmt.Println("⚙️  Compiling templates...")
+
 	// Run compilation
 	if err := compileTemplates(targets); err != nil {
 		return fmt.Errorf("failed to compile templates: %w", err)
@@ -256,51 +482,18 @@ func runSyncCompile(targetFilter string) error {
 	return nil
 }
 
-func runSyncDeploy(targetFilter string) error {
+// runSyncDeploy updates the existing installations and returns the installed files that changed
+func runSyncDeploy(targetFilter string) ([]string, error) {
 	fmt.Println("🚀 Updating existing installations...")
 
-	// Load installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
+	installations, err := syncInstallations(targetFilter)
 	if err != nil {
-		return fmt.Errorf("failed to load installation tracker: %w", err)
-	}
-
-	// Get existing installations
-	installations := tracker.GetInstallations(targetFilter, "")
-
-	// Filter by scope if specified
-	if syncScope != "all" {
-		var filteredInstallations []config.InstallationRecord
-		for _, install := range installations {
-			if syncScope == "global" && install.Global {
-				filteredInstallations = append(filteredInstallations, install)
-			} else if syncScope == "project" && !install.Global {
-				filteredInstallations = append(filteredInstallations, install)
-			}
-		}
-		installations = filteredInstallations
-	}
-
-	// Filter by targets if specified
-	if syncTargets != "" {
-		targetList := strings.Split(syncTargets, ",")
-		targetMap := make(map[string]bool)
-		for _, target := range targetList {
-			targetMap[strings.TrimSpace(target)] = true
-		}
-
-		var filteredInstallations []config.InstallationRecord
-		for _, install := range installations {
-			if targetMap[install.Target] {
-				filteredInstallations = append(filteredInstallations, install)
-			}
-		}
-		installations = filteredInstallations
+		return nil, err
 	}
 
 	if len(installations) == 0 {
 		fmt.Println("  📋 No existing installations found to update")
-		return nil
+		return nil, nil
 	}
 
 	// Temporarily set force flag for update operations
@@ -314,9 +507,12 @@ func runSyncDeploy(targetFilter string) error {
 	updated := 0
 	failed := 0
 	unchanged := 0
+	var written []string
 
 	for _, installation := range installations {
-		status, err := updateSingleInstallationWithStatus(installation)
+		status, files, err := updateSingleInstallationWithStatus(installation)
+		written = append(written, files...)
+		recordInstallationUpdate(installation, status, err)
 		if err != nil {
 			fmt.Printf("    ⚠️  Failed to update %s %s: %v\n", installation.Target, installation.Rule, err)
 			failed++
@@ -357,200 +553,189 @@ func runSyncDeploy(targetFilter string) error {
 		fmt.Println("  ⏸️  All installations are up to date")
 	}
 
-	return nil
+	return written, nil
 }
 
-func showVendorStatus() error {
-	// Load config
-	cfg := config.NewDefaultConfig()
-	if viper.ConfigFileUsed() != "" {
-		if err := viper.Unmarshal(cfg); err != nil {
-			return err
+// syncInstallations returns the existing installations selected by the target argument,
+// --scope, --targets and --project
+func syncInstallations(targetFilter string) ([]config.InstallationRecord, error) {
+	// Load global and in-project installation trackers
+	tracker, err := loadInstallationTrackers(syncProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load installation tracker: %w", err)
+	}
+	return selectSyncInstallations(tracker, targetFilter)
+}
+
+// selectSyncInstallations filters the installations of tracker like syncInstallations
+func selectSyncInstallations(tracker *config.InstallationTracker, targetFilter string) ([]config.InstallationRecord, error) {
+	// Get existing installations
+	installations := tracker.GetInstallations(targetFilter, "")
+
+	// Filter by project if specified
+	if syncProject != "" {
+		projectPath, err := resolveProjectPath(syncProject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve project path: %w", err)
+		}
+
+		var filteredInstallations []config.InstallationRecord
+		for _, install := range installations {
+			if !install.Global && filepath.Clean(install.ProjectPath) == filepath.Clean(projectPath) {
+				filteredInstallations = append(filteredInstallations, install)
+			}
 		}
+		installations = filteredInstallations
 	}
 
-	// Create vendor manager
-	manager := vendor.NewManager(cfg)
-	if err := manager.LoadLockFile(); err != nil {
-		return err
+	// Filter by scope if specified
+	if syncScope != "all" {
+		var filteredInstallations []config.InstallationRecord
+		for _, install := range installations {
+			if syncScope == "global" && install.Global {
+				filteredInstallations = append(filteredInstallations, install)
+			} else if syncScope == "project" && !install.Global {
+				filteredInstallations = append(filteredInstallations, install)
+			}
+		}
+		installations = filteredInstallations
 	}
 
-	// Show vendor status
-	return manager.Status()
-}
+	// Filter by targets if specified
+	if syncTargets != "" {
+		targetList := strings.Split(syncTargets, ",")
+		targetMap := make(map[string]bool)
+		for _, target := range targetList {
+			targetMap[strings.TrimSpace(target)] = true
+		}
 
-func showInstallationStatus(targetFilter string) error {
-	// Load installation tracker
-	tracker, err := config.LoadGlobalInstallationTracker()
-	if err != nil {
-		return err
+		var filteredInstallations []config.InstallationRecord
+		for _, install := range installations {
+			if targetMap[install.Target] {
+				filteredInstallations = append(filteredInstallations, install)
+			}
+		}
+		installations = filteredInstallations
 	}
 
-	// Get installations
-	installations := tracker.GetInstallations(targetFilter, "")
+	return installations, nil
+}
 
-	if len(installations) == 0 {
-		fmt.Println("  📋 No installations found")
-		return nil
+func showVendorStatus() error {
+	// Load config
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return err
 	}
 
-	// Group by target for display
-	targetGroups := make(map[string][]config.InstallationRecord)
-	for _, install := range installations {
-		targetGroups[install.Target] = append(targetGroups[install.Target], install)
+	// Create vendor manager
+	manager := vendor.NewManager(cfg)
+	if err := manager.LoadLockFile(); err != nil {
+		return err
 	}
 
-	for target, installs := range targetGroups {
-		fmt.Printf("  📊 %s: %d installation(s)\n", target, len(installs))
-	}
+	// Show vendor status
+	return manager.Status()
+}
 
-	return nil
+func runSyncGitPull() ([]string, error) {
+	return pullTemplateRepository(git.DefaultGitRepositoryFactory())
 }
 
-func runSyncGitPull() error {
+// pullTemplateRepository pulls the template repository in the current directory, lists the
+// files that changed and returns those that were added or modified. Repositories with
+// uncommitted changes are left alone.
+func pullTemplateRepository(factory git.RepositoryFactory) ([]string, error) {
 	fmt.Println("📥 Pulling template repository...")
 
 	// Get current working directory (already template dir due to root.go)
 	currentDir, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	// Try to open as git repository
-	repo, err := gogit.PlainOpen(currentDir)
-	if err != nil {
-		// Not a git repo - silently continue
-		return nil //nolint:nilerr // Intentional: we want to continue gracefully when not in a git repo
-	}
-
-	// Get worktree to check status
-	worktree, err := repo.Worktree()
-	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+	// Not a git repo - silently continue
+	repo := factory.NewRepository("", currentDir)
+	if !repo.Exists() {
+		return nil, nil
 	}
 
 	// Check if repository is dirty
-	status, err := worktree.Status()
+	status, err := repo.Status()
 	if err != nil {
-		return fmt.Errorf("failed to get repository status: %w", err)
+		return nil, err
 	}
-
-	if !status.IsClean() {
+	if len(status) > 0 {
 		fmt.Println("  ⚠️  Template repository has uncommitted changes, skipping git pull:")
-		// List changed files
-		for file, stat := range status {
-			if stat.Worktree != gogit.Unmodified {
-				var statusChar string
-				switch stat.Worktree {
-				case gogit.Modified:
-					statusChar = "M"
-				case gogit.Added:
-					statusChar = "A"
-				case gogit.Deleted:
-					statusChar = "D"
-				case gogit.Renamed:
-					statusChar = "R"
-				case gogit.Copied:
-					statusChar = "C"
-				case gogit.UpdatedButUnmerged:
-					statusChar = "U"
-				default:
-					statusChar = "?"
-				}
-				fmt.Printf("      %s %s\n", statusChar, file)
-			}
+		for _, change := range status {
+			fmt.Printf("      %s %s\n", change.Status, change.Path)
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Get current commit before pull
-	head, err := repo.Head()
+	oldCommit, err := repo.GetCurrentCommit()
 	if err != nil {
-		return fmt.Errorf("failed to get HEAD: %w", err)
+		return nil, err
 	}
-	oldCommit := head.Hash()
 
 	// Perform pull
-	err = worktree.Pull(&gogit.PullOptions{})
-	if err != nil {
-		if err == gogit.NoErrAlreadyUpToDate {
-			fmt.Println("  ✅ Template repository is already up to date")
-			return nil
-		}
-
+	if err := repo.Pull(); err != nil {
 		// Pull failed - ask user if they want to continue
 		fmt.Printf("  ❌ Git pull failed: %v\n", err)
 		if !syncForce {
-			fmt.Print("  Continue anyway? [y/N]: ")
-			var response string
-			if _, err := fmt.Scanln(&response); err != nil {
-				return fmt.Errorf("sync cancelled by user")
+			proceed, err := prompts.Confirm("  Continue anyway?")
+			if err != nil {
+				return nil, err
 			}
-			if response != "y" && response != "Y" && response != "yes" {
-				return fmt.Errorf("sync cancelled by user")
+			if !proceed {
+				return nil, fmt.Errorf("sync cancelled by user")
 			}
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Get new commit after pull
-	newHead, err := repo.Head()
+	newCommit, err := repo.GetCurrentCommit()
 	if err != nil {
-		return fmt.Errorf("failed to get new HEAD: %w", err)
+		return nil, err
+	}
+	if oldCommit == newCommit {
+		fmt.Println("  ✅ Template repository is already up to date")
+		return nil, nil
 	}
-	newCommit := newHead.Hash()
-
-	// Show summary if commits changed
-	if oldCommit != newCommit {
-		fmt.Printf("  ✅ Pulled changes from %s to %s\n", oldCommit.String()[:8], newCommit.String()[:8])
 
-		// Get the commit objects to show changed files
-		oldCommitObj, err := repo.CommitObject(oldCommit)
-		if err != nil {
-			return nil //nolint:nilerr // Intentional: skip showing changes if we can't get commit objects
-		}
-		newCommitObj, err := repo.CommitObject(newCommit)
-		if err != nil {
-			return nil //nolint:nilerr // Intentional: skip showing changes if we can't get commit objects
-		}
+	// Show summary of the pulled changes
+	fmt.Printf("  ✅ Pulled changes from %s to %s\n", shortCommit(oldCommit), shortCommit(newCommit))
+	changes, err := repo.DiffFiles(oldCommit, newCommit)
+	if err != nil {
+		return nil, nil //nolint:nilerr // Intentional: skip showing changes if the diff cannot be calculated
+	}
 
-		// Get the trees for both commits
-		oldTree, err := oldCommitObj.Tree()
-		if err != nil {
-			return nil //nolint:nilerr // Intentional: skip showing changes if we can't get trees
-		}
-		newTree, err := newCommitObj.Tree()
-		if err != nil {
-			return nil //nolint:nilerr // Intentional: skip showing changes if we can't get trees
+	var changed []string
+	if len(changes) > 0 {
+		fmt.Println("  📝 Changed files:")
+		printGitChanges(changes)
+		for _, change := range changes {
+			if change.Status != git.FileDeleted {
+				changed = append(changed, change.Path)
+			}
 		}
+	}
 
-		// Calculate diff
-		changes, err := oldTree.Diff(newTree)
-		if err != nil {
-			return nil //nolint:nilerr // Intentional: skip showing changes if diff calculation fails
-		}
+	return changed, nil
+}
 
-		// Show condensed file list
-		if len(changes) > 0 {
-			fmt.Println("  📝 Changed files:")
-			for _, change := range changes {
-				from, to, err := change.Files()
-				if err != nil {
-					continue // Skip files we can't process
-				}
-				if from == nil && to != nil {
-					// Added file
-					fmt.Printf("      + %s\n", to.Name)
-				} else if from != nil && to == nil {
-					// Deleted file
-					fmt.Printf("      - %s\n", from.Name)
-				} else if from != nil && to != nil {
-					// Modified file
-					fmt.Printf("      M %s\n", to.Name)
-				}
-			}
+// printGitChanges lists files changed between two commits
+func printGitChanges(changes []git.FileChange) {
+	for _, change := range changes {
+		switch change.Status {
+		case git.FileAdded:
+			fmt.Printf("      + %s\n", change.Path)
+		case git.FileDeleted:
+			fmt.Printf("      - %s\n", change.Path)
+		default:
+			fmt.Printf("      M %s\n", change.Path)
 		}
 	}
-
-	return nil
 }
@@ -0,0 +1,453 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/diff"
+	"github.com/ratler/airuler/internal/git"
+	"github.com/ratler/airuler/internal/vendor"
+)
+
+// Statuses of an installed file in a sync plan
+const (
+	planCreate    = "create"
+	planChange    = "change"
+	planUnchanged = "unchanged"
+)
+
+// errDrift is returned by sync --check when installed files are out of date. The command fails
+// with exitDrift.
+var errDrift = errors.New("drift detected")
+
+// compilePlan compares templates rendered in memory with the compiled directory
+type compilePlan struct {
+	changes   []diff.FileChange // paths relative to compiled/
+	unchanged int
+	files     map[string][]byte // rendered rules keyed by path relative to compiled/
+}
+
+// installationPlan is what updating one installation would do to its files
+type installationPlan struct {
+	installation config.InstallationRecord
+	files        []plannedFile
+	err          error
+}
+
+// status returns the status updating the installation would have, like
+// updateSingleInstallationWithStatus: installed when a file would be created, updated when one
+// would change, otherwise unchanged
+func (p installationPlan) status() string {
+	status := ruleUnchanged
+	for _, file := range p.files {
+		switch file.status() {
+		case planCreate:
+			return ruleInstalled
+		case planChange:
+			status = ruleUpdated
+		}
+	}
+	return status
+}
+
+// plannedFile is an installed file with its current content and the content a sync would give it
+type plannedFile struct {
+	path    string
+	current []byte // nil when the file does not exist
+	planned []byte
+}
+
+// status returns planCreate, planChange or planUnchanged
+func (f plannedFile) status() string {
+	switch {
+	case f.current == nil:
+		return planCreate
+	case bytes.Equal(f.current, f.planned):
+		return planUnchanged
+	}
+	return planChange
+}
+
+// change returns the file as a change that can be written as a unified diff
+func (f plannedFile) change() diff.FileChange {
+	return diff.FileChange{Path: strings.TrimPrefix(filepath.ToSlash(f.path), "/"), Old: f.current, New: f.planned}
+}
+
+// pullPlan is what pulling the template repository in the current directory would bring in
+type pullPlan struct {
+	repo    git.Repository
+	dirty   []git.FileChange // uncommitted changes, which make a sync skip the pull
+	from    string
+	to      string
+	changes []git.FileChange
+}
+
+// pending reports whether a pull would change the templates
+func (p *pullPlan) pending() bool {
+	return p != nil && len(p.dirty) == 0 && p.from != p.to
+}
+
+// planPull fetches the template repository and lists the files a pull would change. It returns
+// nil when the current directory is not a git repository.
+func planPull(factory git.RepositoryFactory) (*pullPlan, error) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	repo := factory.NewRepository("", currentDir)
+	if !repo.Exists() {
+		return nil, nil
+	}
+
+	plan := &pullPlan{repo: repo}
+	if plan.dirty, err = repo.Status(); err != nil || len(plan.dirty) > 0 {
+		return plan, err
+	}
+	if plan.from, err = repo.GetCurrentCommit(); err != nil {
+		return nil, err
+	}
+	if plan.to, err = repo.GetRemoteCommit(); err != nil {
+		return nil, fmt.Errorf("failed to fetch template repository: %w", err)
+	}
+	if plan.from == plan.to {
+		return plan, nil
+	}
+	if plan.changes, err = repo.DiffFiles(plan.from, plan.to); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planSyncedCompile plans the compilation of the project as a sync would leave it. When a pull
+// or vendor updates would change it, the pulled templates and the vendors at the versions an
+// update would move them to are exported into a temporary project that is compiled instead.
+func planSyncedCompile(
+	targets []compiler.Target,
+	pull *pullPlan,
+	manager *vendor.Manager,
+	statuses []vendor.VendorStatus,
+	vendorUpdates int,
+) (*compilePlan, error) {
+	if !pull.pending() && vendorUpdates == 0 {
+		return planCompile(compileSource{}, targets)
+	}
+
+	if manager == nil {
+		var err error
+		if manager, err = readVendorManager(); err != nil {
+			return nil, err
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "airuler-plan-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The project's own templates come from the pull, or from the working tree without one
+	source := compileSource{root: dir}
+	if pull.pending() {
+		if err := pull.repo.ExportTree(pull.to, "templates", filepath.Join(dir, "templates")); err != nil {
+			return nil, fmt.Errorf("failed to export templates at %s: %w", shortCommit(pull.to), err)
+		}
+	} else if source.templates, err = filepath.Abs("templates"); err != nil {
+		return nil, fmt.Errorf("failed to resolve templates: %w", err)
+	}
+
+	skipped, err := manager.ExportUpdates(dir, statuses)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range skipped {
+		fmt.Printf("  ⚠️  %s: compiled at its locked version; only git vendor updates can be previewed\n", name)
+	}
+	return planCompile(source, targets)
+}
+
+// planCompile renders the templates of a project for targets in memory and compares them with
+// compiled/ of the current directory
+func planCompile(source compileSource, targets []compiler.Target) (*compilePlan, error) {
+	rendered, err := source.render(targets, false)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &compilePlan{files: rendered.targetFiles()}
+
+	current, err := readCompiledRules(targets)
+	if err != nil {
+		return nil, err
+	}
+	plan.changes = diff.Compare(current, plan.files)
+	for path, content := range plan.files {
+		if old, exists := current[path]; exists && bytes.Equal(old, content) {
+			plan.unchanged++
+		}
+	}
+	return plan, nil
+}
+
+// readCompiledRules reads the compiled rules of targets, keyed by path relative to compiled/
+func readCompiledRules(targets []compiler.Target) (map[string][]byte, error) {
+	selected := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		selected[string(target)] = true
+	}
+	return diff.ReadTree("compiled", func(path string) bool {
+		target, _, found := strings.Cut(path, "/")
+		return found && selected[target]
+	})
+}
+
+// planInstallation works out what updating an installation from rules, keyed by path
+// relative to compiled/, would do to the installed files. It follows
+// updateSingleInstallationWithStatus without writing anything.
+func planInstallation(installation config.InstallationRecord, rules map[string][]byte) installationPlan {
+	plan := installationPlan{installation: installation}
+
+	target := compiler.Target(installation.Target)
+	if !isValidTarget(target) {
+		plan.err = fmt.Errorf("invalid target: %s", installation.Target)
+		return plan
+	}
+
+	var names []string
+	for path := range rules {
+		if name, found := strings.CutPrefix(path, installation.Target+"/"); found && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	selected := installationFiles(installation.Rule, names)
+	if len(selected) == 0 {
+		plan.err = fmt.Errorf("no compiled rules found for %s", installation.Rule)
+		return plan
+	}
+
+	targetDir, err := installationTargetDir(installation)
+	if err != nil {
+		plan.err = err
+		return plan
+	}
+
+	for _, name := range selected {
+		file := plannedFile{path: filepath.Join(targetDir, name), planned: rules[installation.Target+"/"+name]}
+		current, err := os.ReadFile(file.path)
+		switch {
+		case err == nil:
+			file.current = current
+			// Memory files are appended to rather than replaced
+			if target == compiler.TargetClaude && installation.Mode == "memory" && !bytes.Equal(current, file.planned) {
+				file.planned = appendMemoryContent(current, file.planned)
+			}
+		case !os.IsNotExist(err):
+			plan.err = fmt.Errorf("failed to read %s: %w", file.path, err)
+			return plan
+		}
+		plan.files = append(plan.files, file)
+	}
+	return plan
+}
+
+// printPullPlan prints the files a pull would change and returns their number
+func printPullPlan(plan *pullPlan) int {
+	switch {
+	case plan == nil:
+		fmt.Println("  📋 Not a git repository; nothing to pull")
+		return 0
+	case len(plan.dirty) > 0:
+		fmt.Println("  ⚠️  Uncommitted changes; the pull would be skipped:")
+		for _, change := range plan.dirty {
+			fmt.Printf("      %s %s\n", change.Status, change.Path)
+		}
+		return 0
+	case !plan.pending():
+		fmt.Printf("  ✅ Up to date (%s)\n", shortCommit(plan.from))
+		return 0
+	}
+
+	fmt.Printf("  ⬇️  %s -> %s\n", shortCommit(plan.from), shortCommit(plan.to))
+	printGitChanges(plan.changes)
+	return len(plan.changes)
+}
+
+// printVendorPlan prints where each vendor would move and returns the number of updates
+func printVendorPlan(statuses []vendor.VendorStatus) int {
+	if len(statuses) == 0 {
+		fmt.Println("  📋 No vendors")
+		return 0
+	}
+
+	updates := 0
+	for _, status := range statuses {
+		switch {
+		case status.Err != nil:
+			fmt.Printf("  ❌ %s: %v\n", status.Name, status.Err)
+		case status.Missing:
+			fmt.Printf("  ⚠️  %s: missing (locked at %s)\n", status.Name, status.Current)
+		case status.UpdateAvailable:
+			latest := status.Latest.String()
+			if commit := shortCommit(status.Latest.Commit); commit != "" && !strings.Contains(latest, commit) {
+				latest += " (" + commit + ")"
+			}
+			fmt.Printf("  ⬆️  %s: %s -> %s\n", status.Name, status.Current, latest)
+			updates++
+		default:
+			fmt.Printf("  ✅ %s: up to date (%s)\n", status.Name, status.Current)
+		}
+	}
+	return updates
+}
+
+// printCompilePlan lists the compiled files that would change and, with --diff, their diff
+func printCompilePlan(plan *compilePlan) error {
+	if len(plan.changes) == 0 {
+		fmt.Printf("  ✅ No changes (%d file(s) unchanged)\n", plan.unchanged)
+		return nil
+	}
+
+	listFileChanges(plan.changes, false)
+	fmt.Printf("  %d file(s) unchanged\n", plan.unchanged)
+	if !syncDiff {
+		return nil
+	}
+	fmt.Println()
+	return diff.WriteUnified(os.Stdout, prefixChanges(plan.changes, "compiled/"))
+}
+
+// printInstallationPlans lists what would happen to each installed file and, with showDiff,
+// the diff of the files that would be created or changed. It returns the number of files per
+// status.
+func printInstallationPlans(plans []installationPlan, showDiff bool) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(plans) == 0 {
+		fmt.Println("  📋 No existing installations found")
+		return counts, nil
+	}
+
+	symbols := map[string]string{planCreate: "+", planChange: "~", planUnchanged: "="}
+	var changes []diff.FileChange
+	for _, plan := range plans {
+		location := "global"
+		if !plan.installation.Global {
+			location = "project: " + plan.installation.ProjectPath
+		}
+		fmt.Printf("  📊 %s %s (%s)\n", plan.installation.Target, plan.installation.Rule, location)
+		if plan.err != nil {
+			fmt.Printf("    ❌ %v\n", plan.err)
+			continue
+		}
+
+		for _, file := range plan.files {
+			status := file.status()
+			counts[status]++
+			fmt.Printf("    %s %-9s %s\n", symbols[status], status, file.path)
+			if status != planUnchanged {
+				changes = append(changes, file.change())
+			}
+		}
+	}
+
+	if !showDiff || len(changes) == 0 {
+		return counts, nil
+	}
+	fmt.Println()
+	return counts, diff.WriteUnified(os.Stdout, changes)
+}
+
+// runSyncCheck compiles the templates with the locked vendors in memory and compares the result
+// with the existing installations. It writes nothing and fails with errDrift when any installed
+// file is out of date.
+func runSyncCheck(targetFilter string) error {
+	fmt.Println("🔍 Checking installations against the templates and locked vendors...")
+
+	// Vendors that are missing or edited in place would make the check meaningless
+	manager, err := readVendorManager()
+	if err != nil {
+		return err
+	}
+	if err := checkVendorsPresent(manager); err != nil {
+		return err
+	}
+	if !syncAllowDirty {
+		if err := checkVendorsClean(manager); err != nil {
+			return err
+		}
+	}
+
+	targets, err := syncTargetList(targetFilter)
+	if err != nil {
+		return err
+	}
+
+	var plans []installationPlan
+	err = runStep("check", func() error {
+		compiled, err := planCompile(compileSource{}, targets)
+		if err != nil {
+			return fmt.Errorf("failed to compile templates: %w", err)
+		}
+		if plans, err = planSyncInstallations(targetFilter, compiled.files); err != nil {
+			return err
+		}
+		if len(plans) == 0 {
+			return fmt.Errorf("no installations to check (deploy with --track-in-project and commit .airuler/installs.yaml)")
+		}
+		for _, plan := range plans {
+			recordInstallationUpdate(plan.installation, plan.status(), plan.err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	counts, err := printInstallationPlans(plans, true)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, plan := range plans {
+		if plan.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d installation(s) could not be checked", failed)
+	}
+	if outdated := counts[planCreate] + counts[planChange]; outdated > 0 {
+		return fmt.Errorf("%w: %d installed file(s) out of date (run 'airuler sync' to update them)", errDrift, outdated)
+	}
+
+	fmt.Printf("\n✅ All %d installed file(s) are up to date\n", counts[planUnchanged])
+	return nil
+}
+
+// checkVendorsPresent fails when a locked vendor is missing, as its templates would silently be
+// left out of the compilation
+func checkVendorsPresent(manager *vendor.Manager) error {
+	var missing []string
+	for _, result := range manager.Verify() {
+		if !result.Present {
+			missing = append(missing, result.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("vendor(s) missing: %s (run 'airuler doctor --fix' to restore them)", strings.Join(missing, ", "))
+}
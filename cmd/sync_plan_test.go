@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/diff"
+	"github.com/ratler/airuler/internal/git"
+	"github.com/spf13/viper"
+)
+
+func TestRunSyncDryRunPlan(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	projectDir := t.TempDir()
+	originalProject, originalNoUpdate, originalDiff := deployProject, syncNoUpdate, syncDiff
+	deployProject = projectDir
+	t.Cleanup(func() { deployProject, syncNoUpdate, syncDiff = originalProject, originalNoUpdate, originalDiff })
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	writeTemplate := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join("templates", name+".tmpl"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+	}
+	writeTemplate("greet", "Hello world")
+	writeTemplate("stable", "Stable rule")
+
+	var err error
+	captureOutput(func() { err = runDeploy("cursor", "") })
+	if err != nil {
+		t.Fatalf("runDeploy() error = %v", err)
+	}
+
+	writeTemplate("greet", "Hello brave world")
+	writeTemplate("extra", "New rule")
+
+	// An older lock file is upgraded in memory only
+	if err := os.WriteFile(config.LockFileName, []byte("vendors: {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	syncNoUpdate, syncDiff = true, true
+	output := captureOutput(func() { err = runSyncDryRun("cursor") })
+	if err != nil {
+		t.Fatalf("runSyncDryRun() error = %v", err)
+	}
+
+	installed := filepath.Join(projectDir, ".cursor", "rules")
+	for _, want := range []string{
+		"+ added     cursor/extra.mdc",
+		"~ modified  cursor/greet.mdc",
+		"1 file(s) unchanged",
+		"--- a/compiled/cursor/greet.mdc",
+		"~ change    " + filepath.Join(installed, "greet.mdc"),
+		"= unchanged " + filepath.Join(installed, "stable.mdc"),
+		"+Hello brave world",
+		"0 installed file(s) created, 1 changed, 1 unchanged",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry run output is missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Pull template repository") {
+		t.Errorf("--no-update should not plan a pull:\n%s", output)
+	}
+
+	// Nothing is written
+	if _, err := os.Stat(filepath.Join("compiled", "cursor", "extra.mdc")); !os.IsNotExist(err) {
+		t.Errorf("dry run should not compile: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(installed, "greet.mdc"))
+	if err != nil || !strings.Contains(string(content), "Hello world") {
+		t.Errorf("dry run should not update installations: %q, %v", content, err)
+	}
+	if content, err := os.ReadFile(config.LockFileName); err != nil || string(content) != "vendors: {}\n" {
+		t.Errorf("dry run should not upgrade the lock file: %q, %v", content, err)
+	}
+	if _, err := os.Stat(config.LockFileName + ".v0.backup"); !os.IsNotExist(err) {
+		t.Errorf("dry run should not back up the lock file: %v", err)
+	}
+}
+
+func TestPlanSyncedCompile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	templatePath := filepath.Join("templates", "greet.tmpl")
+	if err := os.WriteFile(templatePath, []byte("Hello world"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	factory := git.NewMockGitRepositoryFactory()
+	factory.ConfigureRepository("", dir, func(r *git.MockRepository) {
+		r.ShouldExist = true
+		r.MockCurrentCommit = "1111111111aaaa"
+		r.MockRemoteCommit = "2222222222bbbb"
+		r.MockChangedFiles = []git.FileChange{{Path: "templates/greet.tmpl", Status: git.FileModified}}
+		r.MockTrees = map[string]map[string]string{
+			"2222222222bbbb": {"templates/greet.tmpl": "Hello pulled world", "README.md": "readme"},
+		}
+	})
+
+	// The pull is fetched and its files listed
+	pull, err := planPull(factory)
+	if err != nil {
+		t.Fatalf("planPull() error = %v", err)
+	}
+	if !pull.pending() || len(pull.changes) != 1 {
+		t.Fatalf("planPull() = %+v, want one incoming file", pull)
+	}
+	output := captureOutput(func() {
+		if n := printPullPlan(pull); n != 1 {
+			t.Errorf("printPullPlan() = %d, want 1", n)
+		}
+	})
+	for _, want := range []string{"11111111 -> 22222222", "M templates/greet.tmpl"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("pull plan is missing %q:\n%s", want, output)
+		}
+	}
+
+	// The plan compiles the pulled templates without touching the working tree
+	var plan *compilePlan
+	captureOutput(func() { plan, err = planSyncedCompile([]compiler.Target{compiler.TargetCursor}, pull, nil, nil, 0) })
+	if err != nil {
+		t.Fatalf("planSyncedCompile() error = %v", err)
+	}
+	if got := string(plan.files["cursor/greet.mdc"]); !strings.Contains(got, "Hello pulled world") {
+		t.Errorf("cursor/greet.mdc = %q, want the pulled template", got)
+	}
+	if content, err := os.ReadFile(templatePath); err != nil || string(content) != "Hello world" {
+		t.Errorf("planSyncedCompile() changed the templates: %q, %v", content, err)
+	}
+
+	// Without a pull or vendor updates the working tree is compiled
+	pull.to = pull.from
+	if plan, err = planSyncedCompile([]compiler.Target{compiler.TargetCursor}, pull, nil, nil, 0); err != nil {
+		t.Fatalf("planSyncedCompile() error = %v", err)
+	}
+	if got := string(plan.files["cursor/greet.mdc"]); !strings.Contains(got, "Hello world") {
+		t.Errorf("cursor/greet.mdc = %q, want the working tree template", got)
+	}
+}
+
+func TestPlanInstallation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+
+	rules := map[string][]byte{
+		"claude/CLAUDE.md":    []byte("Memory rule"),
+		"cursor/greet.mdc":    []byte("Hello"),
+		"cursor/greeting.mdc": []byte("Hi"),
+	}
+
+	// A new installation creates its file
+	plan := planInstallation(config.InstallationRecord{Target: "cursor", Rule: "greet", ProjectPath: projectDir}, rules)
+	if plan.err != nil || len(plan.files) != 1 || plan.files[0].status() != planCreate {
+		t.Fatalf("plan = %+v, want one file to create", plan)
+	}
+	if want := filepath.Join(projectDir, ".cursor", "rules", "greet.mdc"); plan.files[0].path != want {
+		t.Errorf("path = %s, want %s", plan.files[0].path, want)
+	}
+
+	// Memory files are appended to
+	memoryPath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.WriteFile(memoryPath, []byte("My notes"), 0644); err != nil {
+		t.Fatalf("Failed to write memory file: %v", err)
+	}
+	memory := config.InstallationRecord{Target: "claude", Rule: "CLAUDE", Mode: "memory", ProjectPath: projectDir}
+	plan = planInstallation(memory, rules)
+	if plan.err != nil || len(plan.files) != 1 || plan.files[0].status() != planChange {
+		t.Fatalf("plan = %+v, want the memory file to change", plan)
+	}
+	if got := string(plan.files[0].planned); !strings.HasPrefix(got, "My notes") || !strings.Contains(got, "Memory rule") {
+		t.Errorf("planned memory content = %q", got)
+	}
+
+	// Rules that were not compiled fail the installation
+	plan = planInstallation(config.InstallationRecord{Target: "cursor", Rule: "missing", ProjectPath: projectDir}, rules)
+	if plan.err == nil || !strings.Contains(plan.err.Error(), "no compiled rules found") {
+		t.Errorf("plan error = %v", plan.err)
+	}
+}
+
+func TestRunSyncCheck(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	projectDir := t.TempDir()
+	originalProject, originalSyncProject := deployProject, syncProject
+	deployProject = projectDir
+	t.Cleanup(func() { deployProject, syncProject = originalProject, originalSyncProject })
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	templatePath := filepath.Join("templates", "greet.tmpl")
+	if err := os.WriteFile(templatePath, []byte("Hello world"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	var err error
+	captureOutput(func() { err = runDeploy("cursor", "") })
+	if err != nil {
+		t.Fatalf("runDeploy() error = %v", err)
+	}
+
+	// Up to date installations pass
+	output := captureOutput(func() { err = runSyncCheck("") })
+	if err != nil || !strings.Contains(output, "All 1 installed file(s) are up to date") {
+		t.Fatalf("runSyncCheck() error = %v, output:\n%s", err, output)
+	}
+
+	// A changed template is drift: the difference is printed and nothing is written
+	if err := os.WriteFile(templatePath, []byte("Hello brave world"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	output = captureOutput(func() { err = runSyncCheck("") })
+	if !errors.Is(err, errDrift) || exitCode(err) != exitDrift {
+		t.Errorf("runSyncCheck() error = %v, want drift", err)
+	}
+	if !strings.Contains(output, "+Hello brave world") {
+		t.Errorf("check output should show the difference:\n%s", output)
+	}
+	installed := filepath.Join(projectDir, ".cursor", "rules", "greet.mdc")
+	if content, err := os.ReadFile(installed); err != nil || strings.Contains(string(content), "brave") {
+		t.Errorf("check should not update installations: %q, %v", content, err)
+	}
+	if content, err := os.ReadFile(filepath.Join("compiled", "cursor", "greet.mdc")); err != nil || strings.Contains(string(content), "brave") {
+		t.Errorf("check should not compile to disk: %q, %v", content, err)
+	}
+
+	// A project without installations has nothing to check
+	syncProject = t.TempDir()
+	captureOutput(func() { err = runSyncCheck("") })
+	if err == nil || errors.Is(err, errDrift) || !strings.Contains(err.Error(), "no installations to check") {
+		t.Errorf("runSyncCheck() error = %v, want no installations", err)
+	}
+}
+
+func TestRunSyncCheckWritesNothing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	templateDir := t.TempDir()
+	t.Chdir(templateDir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	projectDir := t.TempDir()
+	originalProject, originalSyncProject, originalTrack := deployProject, syncProject, deployTrackInProject
+	deployProject, syncProject, deployTrackInProject = projectDir, projectDir, true
+	t.Cleanup(func() {
+		deployProject, syncProject, deployTrackInProject = originalProject, originalSyncProject, originalTrack
+	})
+
+	if err := os.MkdirAll("templates", 0755); err != nil {
+		t.Fatalf("Failed to create templates directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("templates", "greet.tmpl"), []byte("Hello world"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	var err error
+	captureOutput(func() { err = runDeploy("cursor", "") })
+	if err != nil {
+		t.Fatalf("runDeploy() error = %v", err)
+	}
+
+	// Files written by an older airuler would be upgraded by a regular load
+	if err := os.WriteFile(config.LockFileName, []byte("vendors: {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	trackerPath := config.ProjectInstallationTrackerPath(projectDir)
+	tracker, err := os.ReadFile(trackerPath)
+	if err != nil {
+		t.Fatalf("Failed to read tracker: %v", err)
+	}
+	unversioned := strings.Replace(string(tracker), fmt.Sprintf("version: %d\n", config.InstallationTrackerVersion), "", 1)
+	if unversioned == string(tracker) {
+		t.Fatalf("tracker has no version to remove:\n%s", tracker)
+	}
+	if err := os.WriteFile(trackerPath, []byte(unversioned), 0600); err != nil {
+		t.Fatalf("Failed to write tracker: %v", err)
+	}
+
+	snapshot := func(dir string) map[string][]byte {
+		t.Helper()
+		files, err := diff.ReadTree(dir, nil)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", dir, err)
+		}
+		return files
+	}
+	dirs := []string{templateDir, projectDir}
+	before := make(map[string]map[string][]byte)
+	for _, dir := range dirs {
+		before[dir] = snapshot(dir)
+	}
+
+	output := captureOutput(func() { err = runSyncCheck("") })
+	if err != nil {
+		t.Fatalf("runSyncCheck() error = %v, output:\n%s", err, output)
+	}
+
+	// Both trees are byte-identical afterwards
+	for _, dir := range dirs {
+		for _, change := range diff.Compare(before[dir], snapshot(dir)) {
+			t.Errorf("runSyncCheck() %s %s", change.Status(), filepath.Join(dir, change.Path))
+		}
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/ratler/airuler/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -147,7 +149,7 @@ func TestRunSyncGitPull(t *testing.T) {
 		}
 
 		// Should silently continue without error
-		err := runSyncGitPull()
+		_, err := runSyncGitPull()
 		if err != nil {
 			t.Errorf("Expected no error for non-git directory, got: %v", err)
 		}
@@ -205,7 +207,7 @@ func TestRunSyncGitPull(t *testing.T) {
 
 		// Capture output to check behavior with clean repo
 		output := captureOutput(func() {
-			err = runSyncGitPull()
+			_, err = runSyncGitPull()
 		})
 
 		// Should handle the case gracefully (git pull will fail but continue with force flag)
@@ -270,7 +272,7 @@ func TestRunSyncGitPull(t *testing.T) {
 
 		// Capture output to verify warning message
 		output := captureOutput(func() {
-			err = runSyncGitPull()
+			_, err = runSyncGitPull()
 		})
 
 		if err != nil {
@@ -499,3 +501,109 @@ func TestSyncCommandIntegration(t *testing.T) {
 		t.Error("Help text should mention --no-git-pull flag")
 	}
 }
+
+func TestPullTemplateRepository(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	newFactory := func(configure func(*git.MockRepository)) (*git.MockRepositoryFactory, *git.MockRepository) {
+		factory := git.NewMockGitRepositoryFactory()
+		var repo *git.MockRepository
+		factory.ConfigureRepository("", dir, func(r *git.MockRepository) {
+			r.ShouldExist = true
+			r.MockCurrentCommit = "1111111111aaaa"
+			configure(r)
+			repo = r
+		})
+		return factory, repo
+	}
+
+	t.Run("pulls and lists changed files", func(t *testing.T) {
+		factory, repo := newFactory(func(r *git.MockRepository) {
+			r.MockPulledCommit = "2222222222bbbb"
+			r.MockChangedFiles = []git.FileChange{
+				{Path: "templates/new.tmpl", Status: git.FileAdded},
+				{Path: "templates/old.tmpl", Status: git.FileDeleted},
+				{Path: "templates/style.tmpl", Status: git.FileModified},
+			}
+		})
+
+		var changed []string
+		var err error
+		output := captureOutput(func() { changed, err = pullTemplateRepository(factory) })
+		if err != nil {
+			t.Fatalf("pullTemplateRepository() error = %v", err)
+		}
+		if want := []string{"templates/new.tmpl", "templates/style.tmpl"}; !reflect.DeepEqual(changed, want) {
+			t.Errorf("pullTemplateRepository() = %v, want the added and modified files %v", changed, want)
+		}
+		if !repo.PullCalled {
+			t.Error("Pull() was not called")
+		}
+		for _, want := range []string{
+			"Pulled changes from 11111111 to 22222222",
+			"+ templates/new.tmpl",
+			"- templates/old.tmpl",
+			"M templates/style.tmpl",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("output is missing %q:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		factory, _ := newFactory(func(*git.MockRepository) {})
+		output := captureOutput(func() {
+			if _, err := pullTemplateRepository(factory); err != nil {
+				t.Errorf("pullTemplateRepository() error = %v", err)
+			}
+		})
+		if !strings.Contains(output, "already up to date") {
+			t.Errorf("output = %s, want an up to date message", output)
+		}
+	})
+
+	t.Run("uncommitted changes skip the pull", func(t *testing.T) {
+		factory, repo := newFactory(func(r *git.MockRepository) {
+			r.MockStatus = []git.FileChange{{Path: "templates/style.tmpl", Status: git.FileModified}}
+		})
+		output := captureOutput(func() {
+			if _, err := pullTemplateRepository(factory); err != nil {
+				t.Errorf("pullTemplateRepository() error = %v", err)
+			}
+		})
+		if repo.PullCalled {
+			t.Error("Pull() was called on a dirty repository")
+		}
+		if !strings.Contains(output, "M templates/style.tmpl") {
+			t.Errorf("output = %s, want the modified file listed", output)
+		}
+	})
+
+	t.Run("failed pull continues with force", func(t *testing.T) {
+		originalForce := syncForce
+		syncForce = true
+		defer func() { syncForce = originalForce }()
+
+		factory, _ := newFactory(func(r *git.MockRepository) { r.ShouldFailPull = true })
+		output := captureOutput(func() {
+			if _, err := pullTemplateRepository(factory); err != nil {
+				t.Errorf("pullTemplateRepository() error = %v", err)
+			}
+		})
+		if !strings.Contains(output, "Git pull failed") {
+			t.Errorf("output = %s, want the pull failure reported", output)
+		}
+	})
+
+	t.Run("directories outside git are skipped", func(t *testing.T) {
+		factory := git.NewMockGitRepositoryFactory()
+		if _, err := pullTemplateRepository(factory); err != nil {
+			t.Errorf("pullTemplateRepository() error = %v", err)
+		}
+		if repo := factory.Repositories[":"+dir]; repo == nil || repo.PullCalled {
+			t.Error("Pull() should not be called outside a git repository")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+// installTrackInProject stores project installation records in <project>/.airuler/installs.yaml
+var installTrackInProject bool
+
+// useProjectTracker reports whether records for projectPath belong in the in-project tracker
+func useProjectTracker(projectPath string) bool {
+	if projectPath == "" {
+		return false
+	}
+	return installTrackInProject || config.HasProjectInstallationTracker(projectPath)
+}
+
+// updateTrackerFor runs fn against the tracker that owns installations for the given scope
+func updateTrackerFor(global bool, projectPath string, fn func(*config.InstallationTracker) error) error {
+	if !global && useProjectTracker(projectPath) {
+		return config.UpdateProjectInstallationTracker(projectPath, fn)
+	}
+	return config.UpdateGlobalInstallationTracker(fn)
+}
+
+// loadInstallationTrackers returns a tracker combining the global tracker with the in-project
+// trackers of the original working directory and any extra project directories given.
+// Records from an in-project tracker take precedence over global records for the same project.
+func loadInstallationTrackers(extraProjects ...string) (*config.InstallationTracker, error) {
+	return mergeInstallationTrackers(config.LoadGlobalInstallationTracker, config.LoadProjectInstallationTracker, extraProjects)
+}
+
+// readInstallationTrackers is loadInstallationTrackers for commands that must not write: older
+// tracker files are upgraded in memory only
+func readInstallationTrackers(extraProjects ...string) (*config.InstallationTracker, error) {
+	return mergeInstallationTrackers(config.ReadGlobalInstallationTracker, config.ReadProjectInstallationTracker, extraProjects)
+}
+
+// mergeInstallationTrackers combines the trackers loaded by loadGlobal and loadProject
+func mergeInstallationTrackers(
+	loadGlobal func() (*config.InstallationTracker, error),
+	loadProject func(string) (*config.InstallationTracker, error),
+	extraProjects []string,
+) (*config.InstallationTracker, error) {
+	globalTracker, err := loadGlobal()
+	if err != nil {
+		return nil, err
+	}
+
+	projectDirs := make(map[string]bool)
+	if wd := GetOriginalWorkingDir(); wd != "" {
+		projectDirs[wd] = true
+	}
+	for _, project := range extraProjects {
+		if project == "" {
+			continue
+		}
+		absPath, err := resolveProjectPath(project)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve project path: %w", err)
+		}
+		projectDirs[absPath] = true
+	}
+
+	// Also pick up trackers of projects known to the global tracker
+	for _, record := range globalTracker.Installations {
+		if !record.Global && record.ProjectPath != "" {
+			projectDirs[record.ProjectPath] = true
+		}
+	}
+
+	dirs := make([]string, 0, len(projectDirs))
+	for dir := range projectDirs {
+		if config.HasProjectInstallationTracker(dir) {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	sort.Strings(dirs)
+
+	merged := &config.InstallationTracker{Installations: []config.InstallationRecord{}}
+	ownedProjects := make(map[string]bool)
+	for _, dir := range dirs {
+		if ownedProjects[dir] {
+			continue
+		}
+		projectTracker, err := loadProject(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load project installation tracker for %s: %w", dir, err)
+		}
+		ownedProjects[dir] = true
+		merged.Installations = append(merged.Installations, projectTracker.Installations...)
+	}
+
+	for _, record := range globalTracker.Installations {
+		if !record.Global && ownedProjects[filepath.Clean(record.ProjectPath)] {
+			continue
+		}
+		merged.Installations = append(merged.Installations, record)
+	}
+
+	return merged, nil
+}
+
+// groupInstallationsByTracker splits installations by the tracker that owns them.
+// Global-tracker records use the empty key.
+func groupInstallationsByTracker(installations []config.InstallationRecord) map[string][]config.InstallationRecord {
+	groups := make(map[string][]config.InstallationRecord)
+	for _, installation := range installations {
+		key := ""
+		if !installation.Global && config.HasProjectInstallationTracker(installation.ProjectPath) {
+			key = installation.ProjectPath
+		}
+		groups[key] = append(groups[key], installation)
+	}
+	return groups
+}
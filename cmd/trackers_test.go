@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ratler/airuler/internal/config"
+)
+
+func TestLoadInstallationTrackersPrefersProjectTracker(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	projectDir := t.TempDir()
+	otherProject := t.TempDir()
+
+	// Global tracker holds a global record, a stale record for projectDir and a record for another project
+	err := config.UpdateGlobalInstallationTracker(func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(config.InstallationRecord{Target: "claude", Rule: "global-rule", Global: true})
+		tracker.AddInstallation(config.InstallationRecord{Target: "cursor", Rule: "stale", ProjectPath: projectDir})
+		tracker.AddInstallation(config.InstallationRecord{Target: "cursor", Rule: "other", ProjectPath: otherProject})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to seed global tracker: %v", err)
+	}
+
+	err = config.UpdateProjectInstallationTracker(projectDir, func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(config.InstallationRecord{
+			Target:      "cursor",
+			Rule:        "shared",
+			ProjectPath: projectDir,
+			FilePath:    filepath.Join(projectDir, ".cursor", "rules", "shared.mdc"),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to seed project tracker: %v", err)
+	}
+
+	tracker, err := loadInstallationTrackers(projectDir)
+	if err != nil {
+		t.Fatalf("loadInstallationTrackers() error = %v", err)
+	}
+
+	rules := make(map[string]bool)
+	for _, record := range tracker.Installations {
+		rules[record.Rule] = true
+	}
+
+	for _, want := range []string{"global-rule", "shared", "other"} {
+		if !rules[want] {
+			t.Errorf("expected merged tracker to contain %q, got %v", want, rules)
+		}
+	}
+	if rules["stale"] {
+		t.Error("global record for a project with its own tracker should be ignored")
+	}
+}
+
+func TestUpdateTrackerForRoutesProjectRecords(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	projectDir := t.TempDir()
+	addRecord := func(tracker *config.InstallationTracker) error {
+		tracker.AddInstallation(config.InstallationRecord{Target: "claude", Rule: "r", ProjectPath: projectDir})
+		return nil
+	}
+
+	// Without the flag or an existing tracker, records go to the global tracker
+	if err := updateTrackerFor(false, projectDir, addRecord); err != nil {
+		t.Fatalf("updateTrackerFor() error = %v", err)
+	}
+	if config.HasProjectInstallationTracker(projectDir) {
+		t.Error("project tracker should not be created without --track-in-project")
+	}
+
+	installTrackInProject = true
+	defer func() { installTrackInProject = false }()
+
+	if err := updateTrackerFor(false, projectDir, addRecord); err != nil {
+		t.Fatalf("updateTrackerFor() error = %v", err)
+	}
+	if !config.HasProjectInstallationTracker(projectDir) {
+		t.Error("expected project tracker to be created with --track-in-project")
+	}
+}
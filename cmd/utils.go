@@ -4,7 +4,9 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -47,8 +49,54 @@ func compileTemplates(targets []compiler.Target) error {
 	return compileTemplatesWithOutput(targets, true)
 }
 
+// compiledRules is the output of compiling templates, held in memory until it is written
+type compiledRules struct {
+	files     map[string][]byte // content keyed by output path, e.g. compiled/cursor/rule.mdc
+	manifest  *config.CompileManifest
+	rules     []ruleResult
+	templates int
+}
+
+// targetFiles returns the compiled files keyed by path relative to compiled/, e.g. cursor/rule.mdc
+func (r *compiledRules) targetFiles() map[string][]byte {
+	files := make(map[string][]byte, len(r.files))
+	for path, content := range r.files {
+		files[strings.TrimPrefix(filepath.ToSlash(path), "compiled/")] = content
+	}
+	return files
+}
+
+// compileSource is the project templates are compiled from
+type compileSource struct {
+	root           string   // project directory; "" is the current directory
+	templates      string   // directory of the project's own templates; "" is templates/ of root
+	includeVendors []string // vendors to compile instead of defaults.include_vendors
+}
+
+// path returns a path inside the project; absolute paths are returned unchanged
+func (s compileSource) path(elem ...string) string {
+	joined := filepath.Join(elem...)
+	if s.root == "" || filepath.IsAbs(joined) {
+		return joined
+	}
+	return filepath.Join(s.root, joined)
+}
+
+// loadLockFile reads the project's lock file; compiling never upgrades it on disk
+func (s compileSource) loadLockFile() (*config.LockFile, error) {
+	return config.ReadLockFile(s.path(config.LockFileName))
+}
+
 // compileTemplatesWithOutput compiles templates with optional output suppression
 func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) error {
+	rendered, err := renderTemplates(targets, showOutput)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rendered.rules {
+		recordRule(rule)
+	}
+
 	// Clean the compiled directory first to ensure a fresh start
 	compiledDir := "compiled"
 	if _, err := os.Stat(compiledDir); err == nil {
@@ -60,37 +108,67 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 		}
 	}
 
-	// Get current working directory for vendor config loading
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+	for _, target := range targets {
+		targetDir := filepath.Join(compiledDir, string(target))
+		if err := os.MkdirAll(targetDir, 0755); err != nil {
+			return fmt.Errorf("failed to create target directory %s: %w", targetDir, err)
+		}
+	}
+	for _, outputPath := range slices.Sorted(maps.Keys(rendered.files)) {
+		if err := os.WriteFile(outputPath, rendered.files[outputPath], 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
 	}
 
-	// Load project configuration
-	var projectConfig *config.Config
-	if viper.ConfigFileUsed() != "" {
-		projectConfig = &config.Config{
-			Defaults: config.DefaultConfig{
-				IncludeVendors: viper.GetStringSlice("defaults.include_vendors"),
-			},
-			VendorOverrides: make(map[string]config.VendorConfig),
+	if err := os.MkdirAll(compiledDir, 0755); err != nil {
+		return fmt.Errorf("failed to create compiled directory: %w", err)
+	}
+	if err := config.SaveCompileManifest(compiledDir, rendered.manifest); err != nil {
+		return err
+	}
+
+	if showOutput {
+		fmt.Printf("\n🎉 Successfully compiled %d rules for %d targets\n", rendered.templates, len(targets))
+	}
+
+	// Update last template directory after successful compilation
+	if currentDir, err := os.Getwd(); err == nil && config.IsTemplateDirectory(currentDir) {
+		if err := config.UpdateLastTemplateDir(currentDir); err != nil && viper.GetBool("verbose") && showOutput {
+			fmt.Printf("Warning: Failed to update last template directory: %v\n", err)
 		}
-		// Load vendor overrides from viper if they exist
-		if viper.IsSet("vendor_overrides") {
-			overrides := viper.GetStringMap("vendor_overrides")
-			for vendorName := range overrides {
-				// Convert the interface{} to VendorConfig - simplified for now
-				projectConfig.VendorOverrides[vendorName] = config.NewDefaultVendorConfig()
-			}
+	}
+
+	return nil
+}
+
+// renderTemplates compiles the templates for the given targets in memory, without touching
+// the compiled directory
+func renderTemplates(targets []compiler.Target, showOutput bool) (*compiledRules, error) {
+	return compileSource{}.render(targets, showOutput)
+}
+
+// render compiles the project's templates for the given targets in memory. Output paths are
+// relative to the project.
+func (s compileSource) render(targets []compiler.Target, showOutput bool) (*compiledRules, error) {
+	// Get the project directory for vendor config loading
+	currentDir := s.root
+	if currentDir == "" {
+		var err error
+		if currentDir, err = os.Getwd(); err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
 		}
-	} else {
-		projectConfig = config.NewDefaultConfig()
+	}
+
+	// Load project configuration, including vendor overrides
+	projectConfig, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	// Load vendor configurations
 	vendorConfigs, err := config.LoadVendorConfigs(currentDir, projectConfig)
 	if err != nil {
-		return fmt.Errorf("failed to load vendor configurations: %w", err)
+		return nil, fmt.Errorf("failed to load vendor configurations: %w", err)
 	}
 
 	// Validate vendor configurations
@@ -102,44 +180,65 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 	}
 
 	// Load templates
-	templateDirs := []string{"templates"}
+	templateDirs := []string{cmp.Or(s.templates, s.path("templates"))}
 
 	// Add vendor directories
-	vendorDirs := getVendorTemplateDirs()
+	vendorDirs := s.vendorTemplateDirs()
 	templateDirs = append(templateDirs, vendorDirs...)
 
 	// Load templates and partials from all directories
-	templates, partialsBySource, err := loadTemplatesFromDirsWithOutput(templateDirs, showOutput)
+	templates, partialsBySource, err := s.loadTemplates(templateDirs, showOutput)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(templates) == 0 {
-		return fmt.Errorf("no templates found in %s", strings.Join(templateDirs, ", "))
+		return nil, fmt.Errorf("no templates found in %s", strings.Join(templateDirs, ", "))
 	}
 
+	// Record where each compiled file came from so installations can carry provenance
+	rendered := &compiledRules{
+		files:     make(map[string][]byte),
+		manifest:  config.NewCompileManifest(),
+		templates: len(templates),
+	}
+	lockFile, err := s.loadLockFile()
+	if err != nil {
+		lockFile = config.NewLockFile()
+	}
+	templateCommit := getTemplateRepoCommit(currentDir)
+
 	// Compile for each target
-	compiled := 0
 	for _, target := range targets {
 		if showOutput {
 			fmt.Printf("Compiling for %s...\n", target)
 		}
 
-		targetDir := filepath.Join("compiled", string(target))
-		if err := os.MkdirAll(targetDir, 0755); err != nil {
-			return fmt.Errorf("failed to create target directory %s: %w", targetDir, err)
-		}
-
 		// Collect memory mode content to handle appending to CLAUDE.md
 		memoryModeContent := []string{}
+		var memoryProvenance []config.CompiledFile
 
 		// Now compile main templates (load source-specific partials for each template)
 		for templateName, templateSource := range templates {
+			// Resolve vendor configuration context for this template
+			templateContext := vendorConfigs.ResolveTemplateContext(templateSource.SourceType, string(target))
+
+			// Vendors can restrict their templates to some targets
+			if !templateContext.CompilationConfig.IncludesTarget(string(target)) {
+				if viper.GetBool("verbose") && showOutput {
+					fmt.Printf("  ⏭️  Skipping %s/%s (not compiled for %s)\n", templateSource.SourceType, templateName, target)
+				}
+				rendered.rules = append(rendered.rules, ruleResult{
+					Target: string(target), Rule: templateName, Status: ruleSkipped, Source: templateSource.SourceType,
+				})
+				continue
+			}
+
 			// Create a fresh compiler for each template to ensure isolation
 			templateComp := compiler.NewCompiler()
 
-			// Load only partials from the same source as this template
-			if sourcePartials, exists := partialsBySource[templateSource.SourceType]; exists {
+			// Load only partials from the same source as this template and the vendors it requires
+			if sourcePartials := s.templatePartials(templateSource.SourceType, partialsBySource, lockFile); len(sourcePartials) > 0 {
 				if viper.GetBool("verbose") && len(sourcePartials) > 0 && showOutput {
 					fmt.Printf(
 						"Loading %d partials for %s template %s...\n",
@@ -174,9 +273,6 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 				frontMatter.Custom = make(map[string]interface{})
 			}
 
-			// Resolve vendor configuration context for this template
-			templateContext := vendorConfigs.ResolveTemplateContext(templateSource.SourceType, string(target))
-
 			// Apply vendor defaults and then override with front matter
 			data := createTemplateData(templateName, *frontMatter, templateContext, string(target))
 
@@ -185,6 +281,9 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 				if showOutput {
 					fmt.Printf("Warning: failed to load template %s: %v\n", templateName, err)
 				}
+				rendered.rules = append(rendered.rules, ruleResult{
+					Target: string(target), Rule: templateName, Status: ruleFailed, Source: templateSource.SourceType, Error: err.Error(),
+				})
 				continue
 			}
 
@@ -193,9 +292,17 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 				if showOutput {
 					fmt.Printf("Warning: failed to compile %s for %s: %v\n", templateName, target, err)
 				}
+				rendered.rules = append(rendered.rules, ruleResult{
+					Target: string(target), Rule: templateName, Status: ruleFailed, Source: templateSource.SourceType, Error: err.Error(),
+				})
 				continue
 			}
 
+			compiledFile := config.CompiledFile{
+				Template:   templateName,
+				Provenance: templateProvenance(templateSource, lockFile, templateCommit),
+			}
+
 			for _, rule := range rules {
 				// Create display name with source information
 				displayName := fmt.Sprintf("%s/%s", templateSource.SourceType, templateName)
@@ -203,18 +310,25 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 				// Special handling for Claude memory mode
 				if target == compiler.TargetClaude && rule.Mode == "memory" {
 					memoryModeContent = append(memoryModeContent, rule.Content)
-					compiled++
+					memoryProvenance = append(memoryProvenance, compiledFile)
+					rendered.rules = append(rendered.rules, ruleResult{
+						Target: string(target), Rule: templateName, Status: ruleCompiled, Mode: rule.Mode,
+						Source: templateSource.SourceType, Path: templateComp.GetOutputPath(target, "CLAUDE.md"),
+					})
 					if showOutput {
 						fmt.Printf("  ✅ %s (memory) -> CLAUDE.md (queued)\n", displayName)
 					}
 				} else {
-					// Regular file writing for non-memory mode
+					// Regular file for non-memory mode
 					outputPath := templateComp.GetOutputPath(target, rule.Filename)
-					if err := os.WriteFile(outputPath, []byte(rule.Content), 0600); err != nil {
-						return fmt.Errorf("failed to write %s: %w", outputPath, err)
-					}
+					rendered.files[outputPath] = []byte(rule.Content)
+					compiledFile.File = filepath.Base(outputPath)
+					rendered.manifest.Add(string(target), compiledFile)
+					rendered.rules = append(rendered.rules, ruleResult{
+						Target: string(target), Rule: templateName, Status: ruleCompiled, Mode: rule.Mode,
+						Source: templateSource.SourceType, Path: outputPath,
+					})
 
-					compiled++
 					modeDesc := ""
 					if rule.Mode != "" && rule.Mode != "command" {
 						modeDesc = fmt.Sprintf(" (%s)", rule.Mode)
@@ -226,7 +340,7 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 			}
 		}
 
-		// Write all collected memory mode content to CLAUDE.md
+		// Combine all collected memory mode content into CLAUDE.md
 		if target == compiler.TargetClaude && len(memoryModeContent) > 0 {
 			// Create a compiler instance just for getting the output path
 			outputComp := compiler.NewCompiler()
@@ -234,27 +348,15 @@ func compileTemplatesWithOutput(targets []compiler.Target, showOutput bool) erro
 			// Use clear section separators that Claude will understand
 			separator := "\n\n---\n\n"
 			combinedContent := strings.Join(memoryModeContent, separator)
-			if err := os.WriteFile(claudeMdPath, []byte(combinedContent), 0600); err != nil {
-				return fmt.Errorf("failed to write CLAUDE.md: %w", err)
-			}
+			rendered.files[claudeMdPath] = []byte(combinedContent)
+			rendered.manifest.Add(string(target), combineProvenance("CLAUDE.md", memoryProvenance))
 			if showOutput {
 				fmt.Printf("  ✅ Combined %d memory templates -> %s\n", len(memoryModeContent), claudeMdPath)
 			}
 		}
 	}
 
-	if showOutput {
-		fmt.Printf("\n🎉 Successfully compiled %d rules for %d targets\n", len(templates), len(targets))
-	}
-
-	// Update last template directory after successful compilation
-	if err == nil && config.IsTemplateDirectory(currentDir) {
-		if err := config.UpdateLastTemplateDir(currentDir); err != nil && viper.GetBool("verbose") && showOutput {
-			fmt.Printf("Warning: Failed to update last template directory: %v\n", err)
-		}
-	}
-
-	return nil
+	return rendered, nil
 }
 
 // loadTemplatesFromDirs loads templates and partials from multiple directories
@@ -262,11 +364,30 @@ func loadTemplatesFromDirs(dirs []string) (map[string]TemplateSource, map[string
 	return loadTemplatesFromDirsWithOutput(dirs, true)
 }
 
-// loadTemplatesFromDirsWithOutput loads templates and partials with optional output suppression
+// loadTemplatesFromDirsWithOutput loads templates and partials with optional output suppression.
+// Templates with the same name in several sources are resolved by the defaults.collisions policy.
 func loadTemplatesFromDirsWithOutput(dirs []string, showOutput bool) (map[string]TemplateSource, map[string]map[string]string, error) {
-	templates := make(map[string]TemplateSource)           // Main templates to compile individually
+	return compileSource{}.loadTemplates(dirs, showOutput)
+}
+
+// loadTemplates loads templates and partials from directories of the project
+func (s compileSource) loadTemplates(dirs []string, showOutput bool) (map[string]TemplateSource, map[string]map[string]string, error) {
+	candidates := make(map[string][]TemplateSource)        // Main templates by name, in source order
 	partialsBySource := make(map[string]map[string]string) // Partials organized by source
-	conflicts := make(map[string][]TemplateSource)         // Track conflicts for reporting
+	vendorRoots := s.vendorRoots()
+
+	projectConfig, err := loadProjectConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := collisionPolicy(projectConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	compilationConfigs, err := s.compilationConfigs(projectConfig)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	for _, dir := range dirs {
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -274,17 +395,7 @@ func loadTemplatesFromDirsWithOutput(dirs []string, showOutput bool) (map[string
 		}
 
 		// Determine source type from directory
-		sourceType := "local"
-		if strings.Contains(dir, "vendors/") {
-			// Extract vendor name from path like "vendors/vendor-name/templates"
-			parts := strings.Split(dir, "/")
-			for i, part := range parts {
-				if part == "vendors" && i+1 < len(parts) {
-					sourceType = parts[i+1]
-					break
-				}
-			}
-		}
+		sourceType := templateDirSourceType(dir, vendorRoots)
 
 		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
@@ -317,41 +428,30 @@ func loadTemplatesFromDirsWithOutput(dirs []string, showOutput bool) (map[string
 			// Check if this is a partial:
 			// 1. Files with .ptmpl extension are always partials
 			// 2. Files in partials/ directory are partials (backward compatibility)
-			pathParts := strings.Split(filepath.ToSlash(relPath), "/")
-			isPartial := ext == ".ptmpl" || slices.Contains(pathParts, "partials")
-
-			if isPartial {
+			if isPartialPath(relPath) {
 				// Initialize partials map for this source if not exists
 				if partialsBySource[sourceType] == nil {
 					partialsBySource[sourceType] = make(map[string]string)
 				}
 				partialsBySource[sourceType][name] = string(content)
 			} else {
-				// Check for conflicts and prioritize local templates
-				if existing, exists := templates[name]; exists {
-					// Track conflicts for later reporting
-					if _, hasConflict := conflicts[name]; !hasConflict {
-						conflicts[name] = []TemplateSource{existing}
+				// Apply the source's compilation settings
+				compilation := compilationConfigs[sourceType]
+				if !compilation.IncludesTemplate(filepath.ToSlash(name)) {
+					if viper.GetBool("verbose") && showOutput {
+						fmt.Printf("⏭️  Skipping %s/%s (excluded by compilation settings)\n", sourceType, name)
 					}
-					conflicts[name] = append(conflicts[name], TemplateSource{
-						Content:    string(content),
-						SourceType: sourceType,
-						SourcePath: path,
-					})
-
-					// If existing is local and new is vendor, keep existing local
-					if existing.SourceType == "local" && sourceType != "local" {
-						return nil // Skip adding the vendor template
-					}
-					// If new template is local and existing is vendor, replace with local
-					// (or same precedence level - later one wins)
+					return nil
+				}
+				if compilation.PrefixesNames() {
+					name = prefixedTemplateName(name, sourceType)
 				}
 
-				templates[name] = TemplateSource{
+				candidates[name] = append(candidates[name], TemplateSource{
 					Content:    string(content),
 					SourceType: sourceType,
 					SourcePath: path,
-				}
+				})
 			}
 
 			return nil
@@ -361,44 +461,186 @@ func loadTemplatesFromDirsWithOutput(dirs []string, showOutput bool) (map[string
 		}
 	}
 
-	// Report conflicts in a consolidated manner
-	for templateName, conflictingSources := range conflicts {
-		if len(conflictingSources) > 1 {
-			// Find which template won (the one in templates map)
-			finalTemplate := templates[templateName]
+	templates, collisions, err := resolveTemplateCollisions(candidates, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Report which template won each collision
+	if showOutput || viper.GetBool("verbose") {
+		for _, collision := range collisions {
+			fmt.Printf("⚠️  Template '%s' found in multiple sources\n", collision.name)
+			for _, resolved := range collision.sources {
+				switch {
+				case resolved.ignored:
+					fmt.Printf("  ❌ Ignoring: %s (%s)\n", resolved.source.SourceType, resolved.source.SourcePath)
+				case resolved.name != collision.name:
+					fmt.Printf("  🔀 Renamed: %s (%s) -> %s\n", resolved.source.SourceType, resolved.source.SourcePath, resolved.name)
+				case resolved.source.SourceType == "local":
+					fmt.Printf("  🏠 Using: %s (%s)\n", resolved.source.SourceType, resolved.source.SourcePath)
+				default:
+					fmt.Printf("  ✅ Using: %s (%s)\n", resolved.source.SourceType, resolved.source.SourcePath)
+				}
+			}
+		}
+	}
+
+	return templates, partialsBySource, nil
+}
 
-			// Separate into used and ignored
-			var used TemplateSource
-			var ignored []TemplateSource
+// Template collision policies, set with defaults.collisions
+const (
+	collisionLocalWins = "local-wins" // local templates shadow vendor templates, then vendors by name
+	collisionError     = "error"      // any collision is an error
+	collisionNamespace = "namespace"  // colliding vendor templates are renamed to <vendor>-<name>
+)
 
-			for _, source := range conflictingSources {
-				if source.SourceType == finalTemplate.SourceType && source.SourcePath == finalTemplate.SourcePath {
-					used = source
-				} else {
-					ignored = append(ignored, source)
+// collisionPolicy returns the configured template collision policy
+func collisionPolicy(projectConfig *config.Config) (string, error) {
+	switch policy := projectConfig.Defaults.Collisions; policy {
+	case "":
+		return collisionLocalWins, nil
+	case collisionLocalWins, collisionError, collisionNamespace:
+		return policy, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid defaults.collisions %q: use %s, %s or %s",
+			policy, collisionLocalWins, collisionError, collisionNamespace,
+		)
+	}
+}
+
+// templateCollision records how templates sharing a name were resolved
+type templateCollision struct {
+	name    string
+	sources []resolvedTemplate
+}
+
+// resolvedTemplate is one of the sources of a collision and the name it compiles as
+type resolvedTemplate struct {
+	source  TemplateSource
+	name    string
+	ignored bool
+}
+
+// resolveTemplateCollisions picks the templates to compile when several sources share a name.
+// Collisions are returned sorted by name, with local templates first.
+func resolveTemplateCollisions(
+	candidates map[string][]TemplateSource,
+	policy string,
+) (map[string]TemplateSource, []templateCollision, error) {
+	templates := make(map[string]TemplateSource)
+	var collisions []templateCollision
+
+	names := slices.Sorted(maps.Keys(candidates))
+	for _, name := range names {
+		sources := candidates[name]
+		if len(sources) == 1 {
+			templates[name] = sources[0]
+			continue
+		}
+
+		// Local templates first, then vendors by name, so results don't depend on load order
+		slices.SortStableFunc(sources, func(a, b TemplateSource) int {
+			if (a.SourceType == "local") != (b.SourceType == "local") {
+				if a.SourceType == "local" {
+					return -1
 				}
+				return 1
 			}
+			return strings.Compare(a.SourceType, b.SourceType)
+		})
+		hasLocal := sources[0].SourceType == "local"
 
-			// Display conflict info
-			if showOutput {
-				fmt.Printf("⚠️  Template '%s' found in multiple sources\n", templateName)
+		if policy == collisionError {
+			return nil, nil, fmt.Errorf(
+				"template %s is provided by %s; set defaults.collisions to %s, or exclude it from all but one source",
+				name, describeTemplateSources(sources), collisionNamespace,
+			)
+		}
 
-				// Show what's being used
-				icon := "✅"
-				if used.SourceType == "local" {
-					icon = "🏠"
+		collision := templateCollision{name: name}
+		for i, source := range sources {
+			resolved := resolvedTemplate{source: source, name: name}
+			switch {
+			case i == 0 && (hasLocal || policy == collisionLocalWins):
+				templates[name] = source
+			case policy == collisionLocalWins:
+				resolved.ignored = true
+			default:
+				resolved.name = prefixedTemplateName(name, source.SourceType)
+				if _, exists := candidates[resolved.name]; exists {
+					return nil, nil, fmt.Errorf(
+						"template %s from %s cannot be renamed to %s: a template with that name already exists",
+						name, source.SourceType, resolved.name,
+					)
 				}
-				fmt.Printf("  %s Using: %s (%s)\n", icon, used.SourceType, used.SourcePath)
+				templates[resolved.name] = source
+			}
+			collision.sources = append(collision.sources, resolved)
+		}
+		collisions = append(collisions, collision)
+	}
+
+	return templates, collisions, nil
+}
+
+// describeTemplateSources lists the sources of colliding templates, e.g. "local and acme"
+func describeTemplateSources(sources []TemplateSource) string {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.SourceType
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// isPartialPath reports whether a template path, relative to templates/, names a partial:
+// a .ptmpl file, or any template in a partials/ directory (for backward compatibility)
+func isPartialPath(relPath string) bool {
+	pathParts := strings.Split(filepath.ToSlash(relPath), "/")
+	return filepath.Ext(relPath) == ".ptmpl" || slices.Contains(pathParts, "partials")
+}
+
+// templatePartials returns the partials visible to templates from a source: those of the vendors
+// it requires, transitively, overridden by its own. Partials of required vendors that are not
+// compiled themselves are loaded from their template directories.
+func (s compileSource) templatePartials(
+	sourceType string,
+	partialsBySource map[string]map[string]string,
+	lockFile *config.LockFile,
+) map[string]string {
+	partials := make(map[string]string)
+	visited := make(map[string]bool)
+
+	var collect func(name string)
+	collect = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
 
-				// Show what's being ignored
-				for _, ignoredSource := range ignored {
-					fmt.Printf("  ❌ Ignoring: %s (%s)\n", ignoredSource.SourceType, ignoredSource.SourcePath)
+		for _, dependency := range lockFile.Vendors[name].Requires {
+			collect(dependency)
+		}
+
+		sourcePartials, exists := partialsBySource[name]
+		if !exists && name != sourceType {
+			if lock, locked := lockFile.Vendors[name]; locked {
+				templateDir := s.path(lock.RootPath(name), "templates")
+				if _, loaded, err := s.loadTemplates([]string{templateDir}, false); err == nil {
+					sourcePartials = loaded[name]
+					partialsBySource[name] = sourcePartials
 				}
 			}
 		}
+		maps.Copy(partials, sourcePartials)
 	}
+	collect(sourceType)
 
-	return templates, partialsBySource, nil
+	return partials
 }
 
 // isValidTarget checks if a target is valid
@@ -451,26 +693,85 @@ func stripTemplateFrontMatter(content string) string {
 	return strings.TrimSpace(parts[2])
 }
 
+// vendorRoots maps vendor names to the directories holding their templates/ and airuler.yaml
+func (s compileSource) vendorRoots() map[string]string {
+	roots := make(map[string]string)
+	lockFile, err := s.loadLockFile()
+	if err != nil {
+		return roots
+	}
+	for name, lock := range lockFile.Vendors {
+		roots[name] = s.path(lock.RootPath(name))
+	}
+	return roots
+}
+
+// compilationConfigs maps vendor names to their compilation settings, with the project's
+// vendor_overrides applied
+func (s compileSource) compilationConfigs(projectConfig *config.Config) (map[string]config.CompilationConfig, error) {
+	vendorConfigs, err := config.LoadVendorConfigs(s.path("."), projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vendor configurations: %w", err)
+	}
+	compilationConfigs := make(map[string]config.CompilationConfig)
+	for name, vendorConfig := range vendorConfigs.VendorConfigs {
+		compilationConfigs[name] = vendorConfig.Compilation
+	}
+	return compilationConfigs, nil
+}
+
+// prefixedTemplateName prefixes the file name of a template with its vendor's name,
+// e.g. rules/security becomes rules/acme-security
+func prefixedTemplateName(name, vendorName string) string {
+	dir, base := filepath.Split(name)
+	return dir + vendorName + "-" + base
+}
+
+// templateDirSourceType returns the vendor a template directory belongs to, or "local"
+func templateDirSourceType(dir string, vendorRoots map[string]string) string {
+	cleaned := filepath.Clean(dir)
+	for name, root := range vendorRoots {
+		if cleaned == filepath.Join(root, "templates") {
+			return name
+		}
+	}
+
+	if strings.Contains(dir, "vendors/") {
+		// Extract vendor name from path like "vendors/vendor-name/templates"
+		parts := strings.Split(dir, "/")
+		for i, part := range parts {
+			if part == "vendors" && i+1 < len(parts) {
+				return parts[i+1]
+			}
+		}
+	}
+	return "local"
+}
+
 // getVendorTemplateDirs returns vendor template directories based on configuration
 func getVendorTemplateDirs() []string {
+	return compileSource{}.vendorTemplateDirs()
+}
+
+// vendorTemplateDirs returns the template directories of the vendors the project compiles
+func (s compileSource) vendorTemplateDirs() []string {
 	var vendorDirs []string
 
 	// Load lock file to see what vendors are available
-	lockFile := &config.LockFile{Vendors: make(map[string]config.VendorLock)}
-	if _, err := os.Stat("airuler.lock"); err == nil {
-		data, err := os.ReadFile("airuler.lock")
-		if err == nil {
-			if err := yaml.Unmarshal(data, lockFile); err != nil {
-				// Log warning but continue - we can still use other template sources
-				if viper.GetBool("verbose") {
-					fmt.Printf("Warning: failed to parse airuler.lock: %v\n", err)
-				}
-			}
+	lockFile, err := s.loadLockFile()
+	if err != nil {
+		// Log warning but continue - we can still use other template sources
+		if viper.GetBool("verbose") {
+			fmt.Printf("Warning: failed to parse airuler.lock: %v\n", err)
 		}
+		lockFile = config.NewLockFile()
 	}
 
 	// Load configuration to check include_vendors setting
-	includeVendors := viper.GetStringSlice("defaults.include_vendors")
+	includeVendors := s.includeVendors
+	if len(includeVendors) == 0 {
+		includeVendors = viper.GetStringSlice("defaults.include_vendors")
+	}
 
 	// Debug output for troubleshooting
 	if viper.GetBool("verbose") {
@@ -487,7 +788,7 @@ func getVendorTemplateDirs() []string {
 	// If no include_vendors config is set, include all vendors (backward compatibility)
 	if len(includeVendors) == 0 {
 		for vendorName := range lockFile.Vendors {
-			vendorDir := filepath.Join("vendors", vendorName, "templates")
+			vendorDir := s.path(lockFile.Vendors[vendorName].RootPath(vendorName), "templates")
 			if _, err := os.Stat(vendorDir); err == nil {
 				vendorDirs = append(vendorDirs, vendorDir)
 			}
@@ -501,7 +802,7 @@ func getVendorTemplateDirs() []string {
 	if includeAll {
 		// Include all vendors
 		for vendorName := range lockFile.Vendors {
-			vendorDir := filepath.Join("vendors", vendorName, "templates")
+			vendorDir := s.path(lockFile.Vendors[vendorName].RootPath(vendorName), "templates")
 			if _, err := os.Stat(vendorDir); err == nil {
 				vendorDirs = append(vendorDirs, vendorDir)
 			}
@@ -509,8 +810,8 @@ func getVendorTemplateDirs() []string {
 	} else {
 		// Include only specified vendors
 		for _, includeVendor := range includeVendors {
-			if _, exists := lockFile.Vendors[includeVendor]; exists {
-				vendorDir := filepath.Join("vendors", includeVendor, "templates")
+			if lock, exists := lockFile.Vendors[includeVendor]; exists {
+				vendorDir := s.path(lock.RootPath(includeVendor), "templates")
 				if _, err := os.Stat(vendorDir); err == nil {
 					vendorDirs = append(vendorDirs, vendorDir)
 				}
@@ -561,7 +862,7 @@ func createTemplateData(
 	// Determine Claude mode from front matter, vendor config, or default
 	data.Mode = frontMatter.ClaudeMode
 	if data.Mode == "" && target == "claude" {
-		data.Mode = context.TargetConfig.DefaultMode
+		data.Mode = getValueOrDefault(context.TargetConfig.DefaultMode, context.CompilationConfig.DefaultMode)
 	}
 
 	// Override with front matter fields (front matter always wins)
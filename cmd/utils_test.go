@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ratler/airuler/internal/compiler"
+	"github.com/ratler/airuler/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestTemplateDirSourceType(t *testing.T) {
+	roots := map[string]string{
+		"python": filepath.Join("vendors", ".repos", "github-com-org-packs", "packs", "python"),
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: "templates", want: "local"},
+		{dir: filepath.Join("vendors", "frontend", "templates"), want: "frontend"},
+		{dir: filepath.Join("vendors", ".repos", "github-com-org-packs", "packs", "python", "templates"), want: "python"},
+	}
+
+	for _, tt := range tests {
+		if got := templateDirSourceType(tt.dir, roots); got != tt.want {
+			t.Errorf("templateDirSourceType(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestTemplatePartials(t *testing.T) {
+	lockFile := config.NewLockFile()
+	lockFile.Vendors["app"] = config.VendorLock{Requires: []string{"style"}}
+	lockFile.Vendors["style"] = config.VendorLock{Requires: []string{"base"}}
+	lockFile.Vendors["base"] = config.VendorLock{}
+
+	partialsBySource := map[string]map[string]string{
+		"app":   {"partials/header": "app header"},
+		"style": {"partials/header": "style header", "partials/tone": "style tone"},
+		"base":  {"partials/tone": "base tone", "partials/footer": "base footer"},
+		"local": {"partials/local": "local only"},
+	}
+
+	want := map[string]string{
+		"partials/header": "app header",
+		"partials/tone":   "style tone",
+		"partials/footer": "base footer",
+	}
+	if got := (compileSource{}).templatePartials("app", partialsBySource, lockFile); !reflect.DeepEqual(got, want) {
+		t.Errorf("templatePartials(app) = %v, want %v", got, want)
+	}
+	if got := (compileSource{}).templatePartials("local", partialsBySource, lockFile); len(got) != 1 {
+		t.Errorf("templatePartials(local) = %v, want only local partials", got)
+	}
+}
+
+func TestLoadTemplatesCompilationSettings(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"templates/security.tmpl":                    "local security",
+		"vendors/acme/templates/security.tmpl":       "acme security",
+		"vendors/acme/templates/style.tmpl":          "acme style",
+		"vendors/acme/templates/draft/next.tmpl":     "acme draft",
+		"vendors/acme/templates/partials/tone.ptmpl": "acme tone",
+		"vendors/acme/airuler.yaml": `compilation:
+  exclude: ["draft/**"]
+  prefix: true
+`,
+		"airuler.yaml": `vendor_overrides:
+  acme:
+    compilation:
+      targets: [claude]
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	viper.SetConfigFile("airuler.yaml")
+	t.Cleanup(viper.Reset)
+
+	templates, partialsBySource, err := loadTemplatesFromDirsWithOutput(
+		[]string{"templates", filepath.Join("vendors", "acme", "templates")}, false)
+	if err != nil {
+		t.Fatalf("loadTemplatesFromDirsWithOutput() error = %v", err)
+	}
+
+	var names []string
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"acme-security", "acme-style", "security"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("templates = %v, want %v", names, want)
+	}
+	if templates["security"].SourceType != "local" {
+		t.Errorf("security came from %s, want the local template", templates["security"].SourceType)
+	}
+	if _, exists := partialsBySource["acme"]["partials/tone"]; !exists {
+		t.Error("partials should not be filtered or prefixed")
+	}
+	projectConfig, err := loadProjectConfig()
+	if err != nil {
+		t.Fatalf("loadProjectConfig() error = %v", err)
+	}
+	compilationConfigs, err := (compileSource{}).compilationConfigs(projectConfig)
+	if err != nil {
+		t.Fatalf("compilationConfigs() error = %v", err)
+	}
+	if compilation := compilationConfigs["acme"]; !reflect.DeepEqual(compilation.Targets, []string{"claude"}) {
+		t.Errorf("acme targets = %v, want the project override", compilation.Targets)
+	}
+
+	// A broken vendor airuler.yaml fails loading instead of dropping every vendor's settings
+	if err := os.WriteFile("vendors/acme/airuler.yaml", []byte("compilation: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadTemplatesFromDirsWithOutput([]string{"templates", "vendors/acme/templates"}, false); err == nil ||
+		!strings.Contains(err.Error(), "failed to load vendor configurations") {
+		t.Errorf("loadTemplatesFromDirsWithOutput() error = %v, want the vendor config error", err)
+	}
+}
+
+func TestCreateTemplateDataCompilationDefaultMode(t *testing.T) {
+	context := config.ResolvedTemplateContext{
+		CompilationConfig: config.CompilationConfig{DefaultMode: "memory"},
+	}
+	if data := createTemplateData("style", TemplateFrontMatter{}, context, "claude"); data.Mode != "memory" {
+		t.Errorf("Mode = %q, want the compilation default_mode", data.Mode)
+	}
+
+	context.TargetConfig.DefaultMode = "both"
+	if data := createTemplateData("style", TemplateFrontMatter{}, context, "claude"); data.Mode != "both" {
+		t.Errorf("Mode = %q, want the target default_mode to win", data.Mode)
+	}
+}
+
+func TestResolveTemplateCollisions(t *testing.T) {
+	local := TemplateSource{SourceType: "local", SourcePath: "templates/security.tmpl"}
+	acme := TemplateSource{SourceType: "acme", SourcePath: "vendors/acme/templates/security.tmpl"}
+	beta := TemplateSource{SourceType: "beta", SourcePath: "vendors/beta/templates/security.tmpl"}
+	style := TemplateSource{SourceType: "beta", SourcePath: "vendors/beta/templates/style.tmpl"}
+
+	resolve := func(policy string, sources ...TemplateSource) (map[string]TemplateSource, []templateCollision, error) {
+		return resolveTemplateCollisions(map[string][]TemplateSource{"security": sources, "style": {style}}, policy)
+	}
+	names := func(templates map[string]TemplateSource) []string {
+		var names []string
+		for name, source := range templates {
+			names = append(names, name+"="+source.SourceType)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("local wins over vendors", func(t *testing.T) {
+		templates, collisions, err := resolve(collisionLocalWins, acme, local)
+		if err != nil {
+			t.Fatalf("resolveTemplateCollisions() error = %v", err)
+		}
+		if got := names(templates); !reflect.DeepEqual(got, []string{"security=local", "style=beta"}) {
+			t.Errorf("templates = %v", got)
+		}
+		if len(collisions) != 1 || collisions[0].sources[0].ignored || !collisions[0].sources[1].ignored {
+			t.Errorf("collisions = %+v, want local used and acme ignored", collisions)
+		}
+	})
+
+	t.Run("local wins picks the first vendor by name", func(t *testing.T) {
+		templates, collisions, err := resolve(collisionLocalWins, beta, acme)
+		if err != nil {
+			t.Fatalf("resolveTemplateCollisions() error = %v", err)
+		}
+		if got := names(templates); !reflect.DeepEqual(got, []string{"security=acme", "style=beta"}) {
+			t.Errorf("templates = %v", got)
+		}
+		if len(collisions) != 1 || collisions[0].sources[0].ignored || !collisions[0].sources[1].ignored {
+			t.Errorf("collisions = %+v, want acme used and beta ignored", collisions)
+		}
+	})
+
+	t.Run("error rejects any collision", func(t *testing.T) {
+		_, _, err := resolve(collisionError, local, beta, acme)
+		if err == nil || !strings.Contains(err.Error(), "provided by local, acme and beta") {
+			t.Errorf("resolveTemplateCollisions() error = %v", err)
+		}
+		if _, _, err := resolve(collisionError, acme, beta); err == nil {
+			t.Error("resolveTemplateCollisions() accepted a collision between vendors")
+		}
+		if _, _, err := resolve(collisionError, acme); err != nil {
+			t.Errorf("resolveTemplateCollisions() without collisions error = %v", err)
+		}
+	})
+
+	t.Run("namespace renames colliding vendor templates", func(t *testing.T) {
+		templates, _, err := resolve(collisionNamespace, beta, local, acme)
+		if err != nil {
+			t.Fatalf("resolveTemplateCollisions() error = %v", err)
+		}
+		want := []string{"acme-security=acme", "beta-security=beta", "security=local", "style=beta"}
+		if got := names(templates); !reflect.DeepEqual(got, want) {
+			t.Errorf("templates = %v, want %v", got, want)
+		}
+
+		_, _, err = resolveTemplateCollisions(map[string][]TemplateSource{
+			"security":      {local, acme},
+			"acme-security": {beta},
+		}, collisionNamespace)
+		if err == nil {
+			t.Error("renaming onto an existing template should fail")
+		}
+	})
+}
+
+func TestCollisionPolicy(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	if policy, err := collisionPolicy(cfg); err != nil || policy != collisionLocalWins {
+		t.Errorf("collisionPolicy() = %q, %v; want local-wins by default", policy, err)
+	}
+	cfg.Defaults.Collisions = "vendor-wins"
+	if _, err := collisionPolicy(cfg); err == nil {
+		t.Error("collisionPolicy() accepted an unknown policy")
+	}
+}
+
+func TestCompileVendorRevision(t *testing.T) {
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("defaults.include_vendors", []string{"other"})
+
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"vendors/acme/templates/greet.tmpl":          "Hello {{template \"partials/name\" .}}",
+		"vendors/base/templates/partials/name.ptmpl": "world",
+		"vendors/base/templates/skipped.tmpl":        "Not compiled",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	lockFile := config.NewLockFile()
+	lockFile.Vendors["acme"] = config.VendorLock{Requires: []string{"base"}}
+	lockFile.Vendors["base"] = config.VendorLock{}
+	if err := config.SaveLockFile(filepath.Join(projectDir, config.LockFileName), lockFile); err != nil {
+		t.Fatalf("Failed to save lock file: %v", err)
+	}
+
+	rules, err := compileVendorRevision(projectDir, "acme", []compiler.Target{compiler.TargetCursor})
+	if err != nil {
+		t.Fatalf("compileVendorRevision() error = %v", err)
+	}
+	if got := string(rules["cursor/greet.mdc"]); !strings.Contains(got, "Hello world") {
+		t.Errorf("cursor/greet.mdc = %q, want the vendor's partial rendered", got)
+	}
+	if _, exists := rules["cursor/skipped.mdc"]; exists {
+		t.Error("only the diffed vendor should be compiled")
+	}
+
+	// The working directory, configuration and project are left alone
+	if dir, err := os.Getwd(); err != nil || dir != workDir {
+		t.Errorf("working directory = %s, %v, want %s", dir, err, workDir)
+	}
+	if got := viper.GetStringSlice("defaults.include_vendors"); !reflect.DeepEqual(got, []string{"other"}) {
+		t.Errorf("include_vendors = %v, want it unchanged", got)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "compiled")); !os.IsNotExist(err) {
+		t.Errorf("compileVendorRevision should not write compiled rules: %v", err)
+	}
+}
@@ -6,9 +6,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/ratler/airuler/internal/compiler"
 	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/diff"
 	"github.com/ratler/airuler/internal/vendor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -34,46 +38,86 @@ When a specific vendor is provided, shows detailed configuration for that vendor
 Examples:
   airuler vendors list              # List all vendors with summaries
   airuler vendors list my-rules     # Show detailed config for my-rules vendor`,
-	Args: cobra.MaximumNArgs(1),
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
 	RunE: func(_ *cobra.Command, args []string) error {
-		if len(args) == 0 {
-			return showCombinedVendorList()
-		}
-		return showDetailedVendorConfig(args[0])
+		return runWithResult("vendors list", func() error {
+			if len(args) == 0 {
+				return showCombinedVendorList()
+			}
+			return showDetailedVendorConfig(args[0])
+		})
 	},
 }
 
+// vendorsJobs is how many vendors update, status and check process at once
+var vendorsJobs int
+
 var vendorsStatusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "Show status of all vendors",
-	Long:  `Show the update status of all vendor repositories.`,
+	Use:         "status",
+	Short:       "Show status of all vendors",
+	Long:        `Show the update status of all vendor repositories.`,
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
 	RunE: func(_ *cobra.Command, _ []string) error {
-		manager, err := createVendorManager()
-		if err != nil {
-			return err
-		}
-		return manager.Status()
+		return runWithResult("vendors status", runVendorsStatus)
 	},
 }
 
 var vendorsCheckCmd = &cobra.Command{
-	Use:   "check",
-	Short: "Check for updates without fetching",
-	Long:  `Check for updates in vendor repositories without fetching them.`,
+	Use:         "check",
+	Short:       "Check for updates without fetching",
+	Long:        `Check for updates in vendor repositories without fetching them.`,
+	Annotations: map[string]string{jsonOutputAnnotation: "true"},
 	RunE: func(_ *cobra.Command, _ []string) error {
+		return runWithResult("vendors check", runVendorsStatus)
+	},
+}
+
+var vendorsVerifyAccept bool
+
+var vendorsVerifyCmd = &cobra.Command{
+	Use:   "verify [--accept <vendor>...]",
+	Short: "Check vendors for local modifications",
+	Long: `Compare the files of every vendor with the tree hash recorded in airuler.lock
+and report vendors that were edited in place.
+
+'airuler sync' refuses to compile modified vendors unless given --allow-dirty.
+Restore a modified vendor with 'airuler doctor --fix' after deleting it, or
+accept the edits with 'airuler vendors verify --accept <vendor>', which records
+the current files in airuler.lock. Updating to a new version replaces them.`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if vendorsVerifyAccept && len(args) == 0 {
+			return fmt.Errorf("--accept requires at least one vendor")
+		}
+		if !vendorsVerifyAccept && len(args) > 0 {
+			return fmt.Errorf("vendor names are only accepted with --accept")
+		}
+
 		manager, err := createVendorManager()
 		if err != nil {
 			return err
 		}
-		return manager.Status()
+		if vendorsVerifyAccept {
+			if err := manager.Accept(args); err != nil {
+				return err
+			}
+			for _, name := range args {
+				fmt.Printf("✅ Accepted local changes of %s\n", name)
+			}
+			return nil
+		}
+		return showVendorIntegrity(manager.Verify())
 	},
 }
 
 var vendorsRemoveCmd = &cobra.Command{
 	Use:   "remove <vendor>",
 	Short: "Remove a vendor",
-	Long:  `Remove a vendor repository from the vendors directory.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Remove a vendor repository from the vendors directory.
+
+Vendors required by another vendor cannot be removed. Vendors that were only
+added as requirements are removed once nothing requires them.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
 		manager, err := createVendorManager()
 		if err != nil {
@@ -124,28 +168,54 @@ var vendorsExcludeAllCmd = &cobra.Command{
 var (
 	fetchAlias  string
 	fetchUpdate bool
+	fetchRef    string
+	fetchPath   string
+	fetchSource string
+	fetchCopy   bool
+	fetchSHA256 string
 )
 
 var vendorsAddCmd = &cobra.Command{
-	Use:   "add <git-url>",
+	Use:   "add <git-url|directory|archive>",
 	Short: "Add a new vendor repository",
-	Long: `Add a new vendor repository from a Git URL.
+	Long: `Add a new vendor from a Git URL, a local directory or a .tar.gz/.zip archive.
 
 Examples:
   airuler vendors add https://github.com/user/rules-repo
   airuler vendors add https://github.com/user/rules-repo --as my-rules
-  airuler vendors add https://github.com/user/rules-repo --update`,
+  airuler vendors add https://github.com/user/rules-repo --update
+  airuler vendors add https://github.com/user/rules-repo --ref v1.4.0
+  airuler vendors add https://github.com/user/rules-repo --ref "^1.2"
+  airuler vendors add https://github.com/org/rule-packs --path packs/python
+  airuler vendors add https://github.com/org/rule-packs --path packs/go --as go-rules
+  airuler vendors add ../my-rule-pack
+  airuler vendors add ../my-rule-pack --copy
+  airuler vendors add https://example.com/rules-1.2.0.tar.gz --sha256 <checksum>
+
+--ref accepts a tag, a branch, a full commit hash or a semver constraint
+(^1.2, ~1.4.0, >=1.0 <2.0, 1.x). Constraints pick the highest matching tag.
+A vendors.<name>.ref entry in airuler.yaml overrides the ref in airuler.lock.
+
+--path reads templates/, partials and airuler.yaml from a subdirectory of the
+repository. Vendors added from the same repository share one clone and one ref.
+
+Existing directories are linked into vendors/ so edits show up immediately;
+--copy copies them instead. Archives at a path or URL are extracted and their
+sha256 is recorded in airuler.lock and verified when the vendor is restored.
+Use --source to override the detected source type.
+
+Vendors listed under vendor.requires in the vendor's airuler.yaml are added
+too, along with their own requirements. A required vendor that is already
+present is reused when its version satisfies the requirement.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
 		// Reuse fetch command logic
 		url := args[0]
 
 		// Load config
-		cfg := config.NewDefaultConfig()
-		if viper.ConfigFileUsed() != "" {
-			if err := viper.Unmarshal(cfg); err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
-			}
+		cfg, err := loadProjectConfig()
+		if err != nil {
+			return err
 		}
 
 		// Create vendor manager
@@ -154,7 +224,15 @@ Examples:
 			return fmt.Errorf("failed to load lock file: %w", err)
 		}
 
-		return manager.Fetch(url, fetchAlias, fetchUpdate)
+		return manager.FetchWithOptions(url, vendor.FetchOptions{
+			Alias:  fetchAlias,
+			Ref:    fetchRef,
+			Path:   fetchPath,
+			Source: fetchSource,
+			Copy:   fetchCopy,
+			SHA256: fetchSHA256,
+			Update: fetchUpdate,
+		})
 	},
 }
 
@@ -163,7 +241,9 @@ var vendorsUpdateCmd = &cobra.Command{
 	Short: "Update vendor repositories",
 	Long: `Update vendor repositories to their latest versions.
 
-If no vendors are specified, all vendors will be updated.
+If no vendors are specified, all vendors will be updated. Vendors with a ref
+move to the newest commit the ref allows: the highest tag matching a semver
+constraint, the head of a branch, or stay on a pinned tag or commit.
 
 Examples:
   airuler vendors update              # Update all vendors
@@ -171,11 +251,9 @@ Examples:
   airuler vendors update frontend,backend # Update multiple vendors`,
 	RunE: func(_ *cobra.Command, args []string) error {
 		// Load config
-		cfg := config.NewDefaultConfig()
-		if viper.ConfigFileUsed() != "" {
-			if err := viper.Unmarshal(cfg); err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
-			}
+		cfg, err := loadProjectConfig()
+		if err != nil {
+			return err
 		}
 
 		// Create vendor manager
@@ -196,10 +274,99 @@ Examples:
 		}
 
 		// Update vendors
+		manager.SetConcurrency(vendorsJobs)
 		return manager.Update(vendorNames)
 	},
 }
 
+var vendorsOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Show vendors with newer versions available",
+	Long: `Show each vendor's locked version next to the newest version its ref allows
+and the newest version published upstream.
+
+Use 'airuler vendors update' to move vendors to the latest allowed version.
+Versions beyond the latest allowed require changing the vendor's ref.`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		manager, err := createVendorManager()
+		if err != nil {
+			return err
+		}
+		return showOutdatedVendors(manager.Outdated())
+	},
+}
+
+var (
+	diffCompiled bool
+	diffTargets  string
+	diffNameOnly bool
+)
+
+var vendorsDiffCmd = &cobra.Command{
+	Use:   "diff [vendor...]",
+	Short: "Show what a vendor update would change",
+	Long: `Compare each vendor's locked commit with the commit 'airuler vendors update'
+would move it to, without changing the vendor.
+
+Lists the added, removed and modified templates and partials, followed by a
+unified diff. --compiled also compiles both versions in a temporary directory
+and shows how the compiled rules change for each target. Both versions are
+compiled with the vendors they require at their locked versions.
+
+Only git vendors can be compared. Without arguments every git vendor is.
+
+Examples:
+  airuler vendors diff                       # Review all pending vendor updates
+  airuler vendors diff frontend              # Review one vendor
+  airuler vendors diff frontend --name-only  # List changed templates only
+  airuler vendors diff frontend --compiled --targets claude,cursor`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		manager, err := createVendorManager()
+		if err != nil {
+			return err
+		}
+
+		// Parse vendor names
+		var vendorNames []string
+		for _, arg := range args {
+			for _, name := range strings.Split(arg, ",") {
+				vendorNames = append(vendorNames, strings.TrimSpace(name))
+			}
+		}
+		if len(vendorNames) == 0 {
+			for name, lock := range manager.GetLockFile().Vendors {
+				if lock.SourceType() == config.SourceGit {
+					vendorNames = append(vendorNames, name)
+				}
+			}
+			if len(vendorNames) == 0 {
+				fmt.Println("No git vendors found")
+				return nil
+			}
+			sort.Strings(vendorNames)
+		}
+
+		targets := compiler.AllTargets
+		if diffTargets != "" {
+			targets = nil
+			for _, name := range strings.Split(diffTargets, ",") {
+				target := compiler.Target(strings.TrimSpace(name))
+				if !isValidTarget(target) {
+					return fmt.Errorf("invalid target: %s", target)
+				}
+				targets = append(targets, target)
+			}
+		}
+
+		for _, vendorName := range vendorNames {
+			if err := showVendorDiff(manager, vendorName, targets); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(vendorsCmd)
 
@@ -207,7 +374,10 @@ func init() {
 	vendorsCmd.AddCommand(vendorsAddCmd)
 	vendorsCmd.AddCommand(vendorsUpdateCmd)
 	vendorsCmd.AddCommand(vendorsStatusCmd)
+	vendorsCmd.AddCommand(vendorsOutdatedCmd)
 	vendorsCmd.AddCommand(vendorsCheckCmd)
+	vendorsCmd.AddCommand(vendorsVerifyCmd)
+	vendorsCmd.AddCommand(vendorsDiffCmd)
 	vendorsCmd.AddCommand(vendorsRemoveCmd)
 	vendorsCmd.AddCommand(vendorsIncludeCmd)
 	vendorsCmd.AddCommand(vendorsExcludeCmd)
@@ -217,15 +387,50 @@ func init() {
 	// Add flags for the add command (reuse fetch flags)
 	vendorsAddCmd.Flags().StringVarP(&fetchAlias, "as", "a", "", "alias for the vendor")
 	vendorsAddCmd.Flags().BoolVarP(&fetchUpdate, "update", "u", false, "update if vendor already exists")
+	vendorsAddCmd.Flags().StringVar(&fetchRef, "ref", "",
+		"tag, branch, commit or semver constraint to follow (e.g. v1.2.0, main, ^1.2)")
+	vendorsAddCmd.Flags().StringVar(&fetchPath, "path", "", "subdirectory of the repository containing the rule pack")
+	vendorsAddCmd.Flags().StringVar(&fetchSource, "source", "", "source type: git, local or archive (detected by default)")
+	vendorsAddCmd.Flags().BoolVar(&fetchCopy, "copy", false, "copy a local directory instead of symlinking it")
+	vendorsAddCmd.Flags().StringVar(&fetchSHA256, "sha256", "", "expected sha256 checksum of an archive")
+
+	vendorsVerifyCmd.Flags().BoolVar(&vendorsVerifyAccept, "accept", false,
+		"record the current files of the given vendors as their expected tree hash")
+
+	vendorsDiffCmd.Flags().BoolVar(&diffCompiled, "compiled", false, "also compare the compiled rules of both versions")
+	vendorsDiffCmd.Flags().StringVarP(&diffTargets, "targets", "t", "", "comma-separated list of targets to compile with --compiled")
+	vendorsDiffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "list changed files without the diff")
+
+	for _, command := range []*cobra.Command{vendorsUpdateCmd, vendorsStatusCmd, vendorsCheckCmd} {
+		command.Flags().IntVarP(&vendorsJobs, "jobs", "j", vendor.DefaultConcurrency, "number of vendors to process at once")
+	}
+}
+
+// runVendorsStatus checks every vendor for updates and prints or records the result
+func runVendorsStatus() error {
+	manager, err := createVendorManager()
+	if err != nil {
+		return err
+	}
+	manager.SetConcurrency(vendorsJobs)
+
+	if !jsonOutput() {
+		return manager.Status()
+	}
+
+	lockFile := manager.GetLockFile()
+	for _, status := range manager.CheckStatus() {
+		recordVendor(status.Name, lockFile.Vendors[status.Name], manager.Dependents(status.Name), nil)
+		recordVendorStatus(status)
+	}
+	return nil
 }
 
 func createVendorManager() (*vendor.Manager, error) {
 	// Load config
-	cfg := config.NewDefaultConfig()
-	if viper.ConfigFileUsed() != "" {
-		if err := viper.Unmarshal(cfg); err != nil {
-			return nil, fmt.Errorf("failed to load config: %w", err)
-		}
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create vendor manager
@@ -237,12 +442,31 @@ func createVendorManager() (*vendor.Manager, error) {
 	return manager, nil
 }
 
+// readVendorManager creates a vendor manager like createVendorManager for commands that must not
+// write, leaving an older lock file on disk as it is
+func readVendorManager() (*vendor.Manager, error) {
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	manager := vendor.NewManager(cfg)
+	if err := manager.ReadLockFile(); err != nil {
+		return nil, fmt.Errorf("failed to load lock file: %w", err)
+	}
+
+	return manager, nil
+}
+
+// loadProjectConfig reads the airuler.yaml in use, or returns defaults when there is none.
+// The file is decoded directly because viper ignores the yaml field names.
 func loadProjectConfig() (*config.Config, error) {
-	cfg := config.NewDefaultConfig()
-	if viper.ConfigFileUsed() != "" {
-		if err := viper.Unmarshal(cfg); err != nil {
-			return nil, fmt.Errorf("failed to load config: %w", err)
-		}
+	if viper.ConfigFileUsed() == "" {
+		return config.NewDefaultConfig(), nil
+	}
+	cfg, err := config.LoadConfig(viper.ConfigFileUsed())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	return cfg, nil
 }
@@ -374,13 +598,44 @@ func showCombinedVendorList() error {
 	fmt.Println("📦 Vendors")
 	fmt.Println("==========")
 
-	for vendorName, vendorData := range lockFile.Vendors {
+	vendorNames := make([]string, 0, len(lockFile.Vendors))
+	for vendorName := range lockFile.Vendors {
+		vendorNames = append(vendorNames, vendorName)
+	}
+	sort.Strings(vendorNames)
+
+	for _, vendorName := range vendorNames {
+		vendorData := lockFile.Vendors[vendorName]
+		recordVendor(vendorName, vendorData, manager.Dependents(vendorName), vendorConfigs)
 		fmt.Printf("\n🏷️  %s\n", vendorName)
 
 		// Repository info
 		fmt.Printf("   %-20s %s\n", "URL:", vendorData.URL)
-		fmt.Printf("   %-20s %s\n", "Commit:", vendorData.Commit)
+		if vendorData.Source != "" {
+			fmt.Printf("   %-20s %s\n", "Source:", vendorData.Source)
+		}
+		if vendorData.Path != "" {
+			fmt.Printf("   %-20s %s\n", "Path:", vendorData.Path)
+		}
+		if vendorData.Ref != "" {
+			fmt.Printf("   %-20s %s\n", "Ref:", vendorData.Ref)
+		}
+		if vendorData.Resolved != "" {
+			fmt.Printf("   %-20s %s\n", "Resolved:", vendorData.Resolved)
+		}
+		if vendorData.Commit != "" {
+			fmt.Printf("   %-20s %s\n", "Commit:", vendorData.Commit)
+		}
+		if vendorData.SHA256 != "" {
+			fmt.Printf("   %-20s %s\n", "SHA256:", vendorData.SHA256)
+		}
 		fmt.Printf("   %-20s %s\n", "Fetched:", vendorData.FetchedAt.Format("2006-01-02 15:04:05"))
+		if len(vendorData.Requires) > 0 {
+			fmt.Printf("   %-20s %s\n", "Requires:", strings.Join(vendorData.Requires, ", "))
+		}
+		if dependents := manager.Dependents(vendorName); len(dependents) > 0 {
+			fmt.Printf("   %-20s %s\n", "Required by:", strings.Join(dependents, ", "))
+		}
 
 		// Configuration info (if available)
 		if vendorConfig, exists := vendorConfigs.VendorConfigs[vendorName]; exists {
@@ -407,9 +662,56 @@ func showCombinedVendorList() error {
 		}
 	}
 
+	if hasVendorRequirements(lockFile) {
+		fmt.Println("\n🌳 Dependency Tree")
+		fmt.Println("==================")
+		for _, vendorName := range vendorNames {
+			vendorData := lockFile.Vendors[vendorName]
+			if vendorData.Indirect && len(manager.Dependents(vendorName)) > 0 {
+				continue
+			}
+			fmt.Println(vendorName)
+			printVendorTree(lockFile, vendorData.Requires, "", map[string]bool{vendorName: true})
+		}
+	}
+
 	return nil
 }
 
+// hasVendorRequirements reports whether any vendor requires another
+func hasVendorRequirements(lockFile *config.LockFile) bool {
+	for _, lock := range lockFile.Vendors {
+		if len(lock.Requires) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// printVendorTree prints the vendors required by a vendor below it, marking indirect ones
+func printVendorTree(lockFile *config.LockFile, requires []string, prefix string, seen map[string]bool) {
+	for i, name := range requires {
+		branch, indent := "├── ", "│   "
+		if i == len(requires)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		label := name
+		if lockFile.Vendors[name].Indirect {
+			label += " (indirect)"
+		}
+		if seen[name] {
+			fmt.Printf("%s%s%s (cycle)\n", prefix, branch, name)
+			continue
+		}
+		fmt.Printf("%s%s%s\n", prefix, branch, label)
+
+		seen[name] = true
+		printVendorTree(lockFile, lockFile.Vendors[name].Requires, prefix+indent, seen)
+		delete(seen, name)
+	}
+}
+
 // showDetailedVendorConfig displays detailed configuration for a specific vendor
 func showDetailedVendorConfig(vendorName string) error {
 	// Get current working directory
@@ -445,6 +747,7 @@ func showDetailedVendorConfig(vendorName string) error {
 	if !repoExists && !configExists {
 		return fmt.Errorf("vendor '%s' not found", vendorName)
 	}
+	recordVendor(vendorName, vendorData, manager.Dependents(vendorName), vendorConfigs)
 
 	fmt.Printf("📦 Vendor: %s\n", vendorName)
 	fmt.Println("=" + strings.Repeat("=", len(vendorName)+9))
@@ -453,7 +756,15 @@ func showDetailedVendorConfig(vendorName string) error {
 	if repoExists {
 		fmt.Println("\n📂 Repository Information:")
 		fmt.Printf("   URL:     %s\n", vendorData.URL)
-		fmt.Printf("   Commit:  %s\n", vendorData.Commit)
+		if vendorData.Source != "" {
+			fmt.Printf("   Source:  %s\n", vendorData.Source)
+		}
+		if vendorData.Commit != "" {
+			fmt.Printf("   Commit:  %s\n", vendorData.Commit)
+		}
+		if vendorData.SHA256 != "" {
+			fmt.Printf("   SHA256:  %s\n", vendorData.SHA256)
+		}
 		fmt.Printf("   Fetched: %s\n", vendorData.FetchedAt.Format("2006-01-02 15:04:05"))
 	}
 
@@ -498,3 +809,178 @@ func showDetailedVendorConfig(vendorName string) error {
 
 	return nil
 }
+
+// showOutdatedVendors prints a table of locked, allowed and available vendor versions
+func showOutdatedVendors(vendors []vendor.OutdatedVendor) error {
+	if len(vendors) == 0 {
+		fmt.Println("No vendors found")
+		return nil
+	}
+
+	fmt.Printf("%-25s %-15s %-15s %-15s %-15s\n", "VENDOR", "REF", "CURRENT", "LATEST ALLOWED", "LATEST")
+	fmt.Println(strings.Repeat("-", 89))
+
+	outdated := 0
+	for _, v := range vendors {
+		if v.Err != nil {
+			fmt.Printf("%-25s %-15s %-15s ❌ %v\n", v.Name, getStringOrDefault(v.Ref, "-"), v.Current, v.Err)
+			continue
+		}
+		marker := ""
+		if v.Outdated {
+			marker = " ⬆️"
+			outdated++
+		}
+		fmt.Printf("%-25s %-15s %-15s %-15s %-15s%s\n", v.Name, getStringOrDefault(v.Ref, "-"),
+			v.Current, v.LatestAllowed, getStringOrDefault(v.LatestAvailable, "-"), marker)
+	}
+
+	if outdated > 0 {
+		fmt.Printf("\n%d vendor(s) can be updated with 'airuler vendors update'\n", outdated)
+	} else {
+		fmt.Println("\n✅ All vendors are at the latest allowed version")
+	}
+
+	return nil
+}
+
+// showVendorIntegrity prints the verification result of each vendor and fails when any is modified or missing
+func showVendorIntegrity(results []vendor.VendorIntegrity) error {
+	if len(results) == 0 {
+		fmt.Println("No vendors found")
+		return nil
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case !result.Present:
+			fmt.Printf("❌ %s: missing (run 'airuler doctor --fix' to restore it)\n", result.Name)
+			failed++
+		case result.Err != nil:
+			fmt.Printf("❌ %s: %v\n", result.Name, result.Err)
+			failed++
+		case result.Linked:
+			fmt.Printf("🔗 %s: linked local directory, not verified\n", result.Name)
+		case result.Unverified():
+			fmt.Printf("❔ %s: no tree hash recorded (run 'airuler vendors update %s' to record one)\n", result.Name, result.Name)
+		case result.Modified():
+			fmt.Printf("⚠️  %s: modified locally (tree %s, lock %s; accept with 'airuler vendors verify --accept %s')\n",
+				result.Name, shortCommit(result.Actual), shortCommit(result.Expected), result.Name)
+			failed++
+		default:
+			fmt.Printf("✅ %s: clean\n", result.Name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d vendor(s) failed verification", failed)
+	}
+	return nil
+}
+
+// showVendorDiff prints the template changes a vendor update would bring and, with --compiled,
+// the changes to the compiled rules of each target
+func showVendorDiff(manager *vendor.Manager, vendorName string, targets []compiler.Target) error {
+	result, err := manager.Diff(vendorName)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	if result.UpToDate {
+		fmt.Printf("✅ %s is up to date (%s)\n", vendorName, result.From)
+		return nil
+	}
+
+	fmt.Printf("🔍 %s: %s -> %s\n", vendorName, result.From, result.To)
+	if len(result.Changes) == 0 {
+		fmt.Println("  No template changes")
+	}
+	if err := printFileChanges(result.Changes, "templates/", true); err != nil {
+		return err
+	}
+
+	if !diffCompiled {
+		return nil
+	}
+
+	oldFiles, err := compileVendorRevision(result.OldProject, vendorName, targets)
+	if err != nil {
+		return fmt.Errorf("failed to compile %s at %s: %w", vendorName, result.From, err)
+	}
+	newFiles, err := compileVendorRevision(result.NewProject, vendorName, targets)
+	if err != nil {
+		return fmt.Errorf("failed to compile %s at %s: %w", vendorName, result.To, err)
+	}
+
+	compiledChanges := diff.Compare(oldFiles, newFiles)
+	for _, target := range targets {
+		var changes []diff.FileChange
+		for _, change := range compiledChanges {
+			if path, found := strings.CutPrefix(change.Path, string(target)+"/"); found {
+				change.Path = path
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Printf("\n📄 Compiled %s rules:\n", target)
+		if err := printFileChanges(changes, string(target)+"/", false); err != nil {
+			return err
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// printFileChanges lists changed files and, unless --name-only, prints them as a unified diff
+// with paths under prefix
+func printFileChanges(changes []diff.FileChange, prefix string, templates bool) error {
+	listFileChanges(changes, templates)
+	if diffNameOnly || len(changes) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	return diff.WriteUnified(os.Stdout, prefixChanges(changes, prefix))
+}
+
+// listFileChanges prints one line per changed file, marking partials when templates is set
+func listFileChanges(changes []diff.FileChange, templates bool) {
+	symbols := map[string]string{diff.Added: "+", diff.Removed: "-", diff.Modified: "~"}
+	for _, change := range changes {
+		kind := ""
+		if templates && isPartialPath(change.Path) {
+			kind = " (partial)"
+		}
+		fmt.Printf("  %s %-9s %s%s\n", symbols[change.Status()], change.Status(), change.Path, kind)
+	}
+}
+
+// prefixChanges returns a copy of changes with prefix added to every path
+func prefixChanges(changes []diff.FileChange, prefix string) []diff.FileChange {
+	prefixed := make([]diff.FileChange, len(changes))
+	for i, change := range changes {
+		change.Path = prefix + change.Path
+		prefixed[i] = change
+	}
+	return prefixed
+}
+
+// compileVendorRevision compiles the templates of one vendor in an exported project in memory,
+// keyed by path relative to compiled/. A version without templates compiles to nothing.
+func compileVendorRevision(projectDir, vendorName string, targets []compiler.Target) (map[string][]byte, error) {
+	if _, err := os.Stat(filepath.Join(projectDir, config.VendorsDir, vendorName, "templates")); os.IsNotExist(err) {
+		return map[string][]byte{}, nil
+	}
+
+	// Compile only this vendor; the vendors it requires still provide partials
+	source := compileSource{root: projectDir, includeVendors: []string{vendorName}}
+	rendered, err := source.render(targets, false)
+	if err != nil {
+		return nil, err
+	}
+	return rendered.targetFiles(), nil
+}
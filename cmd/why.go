@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ratler/airuler/internal/config"
+	"github.com/ratler/airuler/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <installed-file>",
+	Short: "Explain where an installed rule file came from",
+	Long: `Why shows the installation records for an installed rule file, including
+the template source (local or vendor), the vendor commit from airuler.lock,
+the template repository commit, the template hash and the airuler version
+that produced it.
+
+Examples:
+  airuler why .cursor/rules/my-rule.mdc
+  airuler why ~/.claude/CLAUDE.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runWhy(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+}
+
+func runWhy(installedFile string) error {
+	filePath, err := resolveInstalledFilePath(installedFile)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := loadInstallationTrackers(findProjectTrackerDir(filepath.Dir(filePath)))
+	if err != nil {
+		return fmt.Errorf("failed to load installation tracker: %w", err)
+	}
+
+	var matches []config.InstallationRecord
+	for _, record := range tracker.Installations {
+		if sameFile(record.FilePath, filePath) {
+			matches = append(matches, record)
+		}
+	}
+
+	if len(matches) == 0 {
+		return fmt.Errorf("%s is not tracked by airuler", filePath)
+	}
+
+	fmt.Printf("📄 %s\n", filePath)
+	for _, record := range matches {
+		fmt.Println()
+		fmt.Printf("  🎯 Target:          %s\n", record.Target)
+		fmt.Printf("  📋 Rule:            %s\n", record.Rule)
+		if record.Mode != "" {
+			fmt.Printf("  ⚙️  Mode:            %s\n", record.Mode)
+		}
+		if record.Global {
+			fmt.Printf("  🌍 Scope:           global\n")
+		} else {
+			fmt.Printf("  📁 Scope:           project (%s)\n", record.ProjectPath)
+		}
+		fmt.Printf("  🕒 Installed:       %s (%s)\n",
+			record.InstalledAt.Format("2006-01-02 15:04:05"), utils.FormatTimeAgo(record.InstalledAt))
+
+		if record.Provenance == (config.Provenance{}) {
+			fmt.Println("  ⚠️  No provenance recorded (installed by an older airuler); run 'airuler sync' to refresh")
+			continue
+		}
+
+		fmt.Printf("  📦 Source:          %s\n", valueOrDash(record.Source))
+		if record.VendorCommit != "" {
+			fmt.Printf("  🔖 Vendor commit:   %s\n", record.VendorCommit)
+		}
+		fmt.Printf("  🔖 Template commit: %s\n", valueOrDash(record.TemplateCommit))
+		fmt.Printf("  #️⃣  Template hash:   %s\n", valueOrDash(record.TemplateHash))
+		fmt.Printf("  🏷️  airuler version: %s\n", valueOrDash(record.AirulerVersion))
+	}
+
+	return nil
+}
+
+// resolveInstalledFilePath resolves a user-supplied path relative to where airuler was started
+func resolveInstalledFilePath(path string) (string, error) {
+	if len(path) > 1 && path[0] == '~' && (path[1] == '/' || path[1] == filepath.Separator) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(GetOriginalWorkingDir(), path)
+	}
+
+	return filepath.Clean(path), nil
+}
+
+// findProjectTrackerDir walks up from dir looking for a project with an in-project tracker
+func findProjectTrackerDir(dir string) string {
+	for {
+		if config.HasProjectInstallationTracker(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// sameFile reports whether two paths refer to the same file, following symlinks where possible
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
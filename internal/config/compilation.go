@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// IncludesTemplate reports whether a template, named by its slash-separated path relative to
+// templates/ without extension, passes the include and exclude globs
+func (c CompilationConfig) IncludesTemplate(name string) bool {
+	if len(c.Include) > 0 && !matchesAnyGlob(c.Include, name) {
+		return false
+	}
+	return !matchesAnyGlob(c.Exclude, name)
+}
+
+// IncludesTarget reports whether templates are compiled for a target
+func (c CompilationConfig) IncludesTarget(target string) bool {
+	return len(c.Targets) == 0 || slices.Contains(c.Targets, target)
+}
+
+// PrefixesNames reports whether output file names are prefixed with the vendor name
+func (c CompilationConfig) PrefixesNames() bool {
+	return c.Prefix != nil && *c.Prefix
+}
+
+// matchesAnyGlob reports whether a template name matches one of the patterns
+func matchesAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, ".tmpl"), ".ptmpl")
+		if matchGlob(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments. Segments are matched with
+// path.Match, and a ** segment matches any number of directories.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
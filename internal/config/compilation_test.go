@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompilationConfig_IncludesTemplate(t *testing.T) {
+	compilation := CompilationConfig{
+		Include: []string{"security", "rules/**"},
+		Exclude: []string{"**/draft-*.tmpl"},
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"security", true},
+		{"style", false},
+		{"rules/python", true},
+		{"rules/lang/go", true},
+		{"rules/draft-rust", false},
+		{"nested/security", false},
+	}
+	for _, tt := range tests {
+		if got := compilation.IncludesTemplate(tt.name); got != tt.want {
+			t.Errorf("IncludesTemplate(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !(CompilationConfig{}).IncludesTemplate("anything/at/all") {
+		t.Error("empty compilation config should include every template")
+	}
+}
+
+func TestCompilationConfig_IncludesTarget(t *testing.T) {
+	if !(CompilationConfig{}).IncludesTarget("cursor") {
+		t.Error("empty compilation config should include every target")
+	}
+	compilation := CompilationConfig{Targets: []string{"claude"}}
+	if !compilation.IncludesTarget("claude") || compilation.IncludesTarget("cursor") {
+		t.Errorf("IncludesTarget() does not follow %v", compilation.Targets)
+	}
+}
+
+func TestMergeCompilationConfig(t *testing.T) {
+	yes, no := true, false
+	base := CompilationConfig{
+		Include:     []string{"*"},
+		Exclude:     []string{"legacy"},
+		Targets:     []string{"claude", "cursor"},
+		Prefix:      &yes,
+		DefaultMode: "command",
+	}
+	override := CompilationConfig{
+		Exclude: []string{},
+		Targets: []string{"cursor"},
+		Prefix:  &no,
+	}
+
+	merged := mergeCompilationConfig(base, override)
+	want := CompilationConfig{
+		Include:     []string{"*"},
+		Exclude:     []string{},
+		Targets:     []string{"cursor"},
+		Prefix:      &no,
+		DefaultMode: "command",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeCompilationConfig() = %+v, want %+v", merged, want)
+	}
+	if merged.PrefixesNames() {
+		t.Error("an override should be able to turn prefixing off")
+	}
+}
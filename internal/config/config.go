@@ -8,13 +8,58 @@ import (
 )
 
 type Config struct {
-	Defaults        DefaultConfig           `yaml:"defaults"`
-	VendorOverrides map[string]VendorConfig `yaml:"vendor_overrides,omitempty"`
+	Defaults        DefaultConfig             `yaml:"defaults"`
+	VendorOverrides map[string]VendorConfig   `yaml:"vendor_overrides,omitempty"`
+	Vendors         map[string]VendorSettings `yaml:"vendors,omitempty"`
+	Git             GitSettings               `yaml:"git,omitempty"`
+	Hooks           HooksConfig               `yaml:"hooks,omitempty"`
+}
+
+// HooksConfig lists shell commands that sync runs before and after compiling and deploying.
+// Commands run in order from the template directory; a failing command aborts the sync.
+type HooksConfig struct {
+	PreCompile  []string `yaml:"pre_compile,omitempty"`
+	PostCompile []string `yaml:"post_compile,omitempty"`
+	PreDeploy   []string `yaml:"pre_deploy,omitempty"`
+	PostDeploy  []string `yaml:"post_deploy,omitempty"`
+}
+
+// GitSettings controls how airuler runs git
+type GitSettings struct {
+	// Backend is go-git (default, built in) or git (the git command line tool, which honours
+	// credential helpers, insteadOf rewrites, partial clones and Git LFS)
+	Backend string `yaml:"backend,omitempty"`
+}
+
+// VendorSettings controls how a vendor repository is fetched and updated
+type VendorSettings struct {
+	// Ref is a tag, branch, full commit hash or semver constraint (e.g. "^1.2");
+	// it takes precedence over the ref recorded in airuler.lock
+	Ref string `yaml:"ref,omitempty"`
+	// Auth selects credentials for a private repository. It is only read from the global
+	// config, where keys may also be URL prefixes such as "github.com/acme"
+	Auth *VendorAuth `yaml:"auth,omitempty"`
+	// Signers are the keys that must sign the vendor's tag or commit: SSH public keys,
+	// armored GPG public keys, or paths to key files. Unsigned or untrusted versions are rejected.
+	Signers []string `yaml:"signers,omitempty"`
+}
+
+// VendorAuth configures authentication for a vendor repository. Secrets are never stored
+// in configuration; tokens and key passphrases are read from environment variables.
+type VendorAuth struct {
+	Method        string `yaml:"method,omitempty"` // ssh-agent, ssh-key, token, netrc, credential-helper or none
+	Username      string `yaml:"username,omitempty"`
+	TokenEnv      string `yaml:"token_env,omitempty"`
+	SSHKey        string `yaml:"ssh_key,omitempty"`
+	PassphraseEnv string `yaml:"passphrase_env,omitempty"`
 }
 
 type DefaultConfig struct {
 	IncludeVendors  []string `yaml:"include_vendors"`
 	LastTemplateDir string   `yaml:"last_template_dir,omitempty"`
+	// Collisions decides between templates of the same name from several sources:
+	// local-wins (default), error or namespace
+	Collisions string `yaml:"collisions,omitempty"`
 }
 
 // VendorConfig represents configuration that can be defined by vendors
@@ -34,6 +79,15 @@ type VendorManifest struct {
 	Version     string `yaml:"version,omitempty"`
 	Author      string `yaml:"author,omitempty"`
 	Homepage    string `yaml:"homepage,omitempty"`
+	// Requires lists other vendors this vendor builds on, e.g. for their partials
+	Requires []VendorRequirement `yaml:"requires,omitempty"`
+}
+
+// VendorRequirement declares a vendor that another vendor depends on
+type VendorRequirement struct {
+	URL     string `yaml:"url"`               // git URL, local directory or archive
+	Version string `yaml:"version,omitempty"` // tag, branch, commit or semver constraint of a git vendor
+	Path    string `yaml:"path,omitempty"`    // subdirectory of the repository holding the rule pack
 }
 
 // TargetConfig contains target-specific configuration
@@ -42,19 +96,33 @@ type TargetConfig struct {
 	// Future fields can be added here as needed
 }
 
-// CompilationConfig contains compilation behavior settings
-// Currently unused but kept for future extensibility
+// CompilationConfig controls which of a vendor's templates are compiled and how
 type CompilationConfig struct {
-	// Reserved for future use
+	Include     []string `yaml:"include,omitempty"`      // template name globs to compile; all when empty
+	Exclude     []string `yaml:"exclude,omitempty"`      // template name globs to skip, applied after include
+	Targets     []string `yaml:"targets,omitempty"`      // targets to compile for; all when empty
+	Prefix      *bool    `yaml:"prefix,omitempty"`       // prefix output file names with the vendor name
+	DefaultMode string   `yaml:"default_mode,omitempty"` // mode for templates without one from front matter or targets
 }
 
 type LockFile struct {
+	Version int                   `yaml:"version"`
 	Vendors map[string]VendorLock `yaml:"vendors"`
 }
 
 type VendorLock struct {
-	URL       string    `yaml:"url"`
-	Commit    string    `yaml:"commit"`
+	URL       string    `yaml:"url"`                 // git URL, local directory, or archive path or URL
+	Source    string    `yaml:"source,omitempty"`    // git (default), local or archive
+	Copy      bool      `yaml:"copy,omitempty"`      // local sources are copied instead of symlinked
+	Ref       string    `yaml:"ref,omitempty"`       // requested tag, branch, commit or semver constraint
+	Resolved  string    `yaml:"resolved,omitempty"`  // tag the ref resolved to, if any
+	Path      string    `yaml:"path,omitempty"`      // subdirectory holding templates/ and airuler.yaml
+	Checkout  string    `yaml:"checkout,omitempty"`  // clone directory under vendors/ shared with other aliases
+	Commit    string    `yaml:"commit,omitempty"`    // commit of git vendors
+	SHA256    string    `yaml:"sha256,omitempty"`    // checksum of an archive or a copied local directory
+	TreeHash  string    `yaml:"tree_hash,omitempty"` // checksum of the vendor's files, for detecting local edits
+	Requires  []string  `yaml:"requires,omitempty"`  // vendors this vendor requires, by name
+	Indirect  bool      `yaml:"indirect,omitempty"`  // added only because another vendor requires it
 	FetchedAt time.Time `yaml:"fetched_at"`
 }
 
@@ -66,9 +134,11 @@ type InstallationRecord struct {
 	Mode        string    `yaml:"mode"`
 	InstalledAt time.Time `yaml:"installed_at"`
 	FilePath    string    `yaml:"file_path"`
+	Provenance  `yaml:",inline"`
 }
 
 type InstallationTracker struct {
+	Version       int                  `yaml:"version"`
 	Installations []InstallationRecord `yaml:"installations"`
 }
 
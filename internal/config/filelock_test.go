@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("replaces existing file and leaves no temp files", func(t *testing.T) {
+		tempDir := t.TempDir()
+		path := filepath.Join(tempDir, "data.yaml")
+
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatalf("Failed to write initial file: %v", err)
+		}
+
+		if err := writeFileAtomic(path, []byte("new"), 0600); err != nil {
+			t.Fatalf("writeFileAtomic error = %v, want nil", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if string(data) != "new" {
+			t.Errorf("File content = %q, want %q", string(data), "new")
+		}
+
+		entries, err := os.ReadDir(tempDir)
+		if err != nil {
+			t.Fatalf("Failed to read directory: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Directory has %d entries, want 1 (temp file left behind?)", len(entries))
+		}
+	})
+
+	t.Run("fails for missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "data.yaml")
+		if err := writeFileAtomic(path, []byte("data"), 0600); err == nil {
+			t.Error("writeFileAtomic into missing directory should return error")
+		}
+	})
+}
+
+func TestWithFileLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "nested", "test.lock")
+
+	called := false
+	err := withFileLock(lockPath, func() error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withFileLock error = %v, want nil", err)
+	}
+	if !called {
+		t.Error("withFileLock did not run callback")
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("Lock file should exist after withFileLock: %v", err)
+	}
+}
@@ -7,22 +7,89 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
-const installTrackerFileName = "airuler.installs"
+const (
+	installTrackerFileName     = "airuler.installs"
+	installTrackerLockFileName = "airuler.installs.lock"
+
+	// ProjectTrackerDir is the directory inside a project that holds project-scoped airuler state
+	ProjectTrackerDir              = ".airuler"
+	projectTrackerFileName         = "installs.yaml"
+	projectTrackerLockFileName     = "installs.lock"
+	projectTrackerGitignoreContent = "# Local lock file, do not commit\n" + projectTrackerLockFileName + "\n"
+)
 
 // LoadInstallationTracker loads the installation tracker from the given directory
 func LoadInstallationTracker(dir string) (*InstallationTracker, error) {
-	tracker := &InstallationTracker{Installations: []InstallationRecord{}}
+	if dir == "" {
+		return &InstallationTracker{Installations: []InstallationRecord{}}, nil
+	}
+
+	trackerPath := filepath.Join(dir, installTrackerFileName)
+	if err := upgradeTrackerFile(trackerPath, filepath.Join(dir, installTrackerLockFileName)); err != nil {
+		return nil, err
+	}
+
+	return loadTrackerFile(trackerPath)
+}
 
+// SaveInstallationTracker saves the installation tracker to the given directory.
+// The file is written atomically while holding the tracker lock.
+func SaveInstallationTracker(dir string, tracker *InstallationTracker) error {
 	if dir == "" {
-		return tracker, nil
+		return fmt.Errorf("directory cannot be empty")
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return withFileLock(filepath.Join(dir, installTrackerLockFileName), func() error {
+		return writeTrackerFile(filepath.Join(dir, installTrackerFileName), tracker)
+	})
+}
+
+// UpdateInstallationTracker runs fn against the tracker stored in dir as a single
+// locked read-modify-write transaction. The tracker is only written back if fn
+// returns nil, so concurrent airuler processes cannot overwrite each other's changes.
+func UpdateInstallationTracker(dir string, fn func(*InstallationTracker) error) error {
+	if dir == "" {
+		return fmt.Errorf("directory cannot be empty")
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	trackerPath := filepath.Join(dir, installTrackerFileName)
+	return withFileLock(filepath.Join(dir, installTrackerLockFileName), func() error {
+		if _, err := InstallationTrackerSchema.MigrateFile(trackerPath); err != nil {
+			return err
+		}
+
+		tracker, err := loadTrackerFile(trackerPath)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tracker); err != nil {
+			return err
+		}
+
+		return writeTrackerFile(trackerPath, tracker)
+	})
+}
+
+// loadTrackerFile reads a tracker file, returning an empty tracker if it doesn't exist
+func loadTrackerFile(trackerPath string) (*InstallationTracker, error) {
+	tracker := &InstallationTracker{Installations: []InstallationRecord{}}
 
 	if _, err := os.Stat(trackerPath); os.IsNotExist(err) {
 		return tracker, nil // File doesn't exist yet
@@ -33,6 +100,12 @@ func LoadInstallationTracker(dir string) (*InstallationTracker, error) {
 		return nil, fmt.Errorf("failed to read installation tracker: %w", err)
 	}
 
+	// Upgrade older files in memory; the upgraded version is persisted on the next write
+	data, _, _, err = InstallationTrackerSchema.MigrateData(data)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := yaml.Unmarshal(data, tracker); err != nil {
 		return nil, fmt.Errorf("failed to parse installation tracker: %w", err)
 	}
@@ -40,24 +113,29 @@ func LoadInstallationTracker(dir string) (*InstallationTracker, error) {
 	return tracker, nil
 }
 
-// SaveInstallationTracker saves the installation tracker to the given directory
-func SaveInstallationTracker(dir string, tracker *InstallationTracker) error {
-	if dir == "" {
-		return fmt.Errorf("directory cannot be empty")
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
+// writeTrackerFile marshals and atomically writes the tracker. Callers must hold the lock.
+func writeTrackerFile(trackerPath string, tracker *InstallationTracker) error {
+	tracker.Version = InstallationTrackerVersion
 
 	data, err := yaml.Marshal(tracker)
 	if err != nil {
 		return fmt.Errorf("failed to marshal installation tracker: %w", err)
 	}
 
-	trackerPath := filepath.Join(dir, installTrackerFileName)
-	return os.WriteFile(trackerPath, data, 0600)
+	return writeFileAtomic(trackerPath, data, 0600)
+}
+
+// upgradeTrackerFile migrates an outdated tracker file on disk, keeping a backup of the original.
+// It takes the tracker lock, so it must not be called while already holding it.
+func upgradeTrackerFile(trackerPath, lockPath string) error {
+	version, err := InstallationTrackerSchema.FileVersion(trackerPath)
+	if err != nil || version >= InstallationTrackerVersion {
+		// Parse errors and newer versions are reported by loadTrackerFile
+		return nil
+	}
+
+	_, err = migrateTrackerFile(trackerPath, lockPath)
+	return err
 }
 
 // AddInstallation adds a new installation record to the tracker
@@ -119,6 +197,17 @@ func LoadGlobalInstallationTracker() (*InstallationTracker, error) {
 	return LoadInstallationTracker(configDir)
 }
 
+// ReadGlobalInstallationTracker loads the global installation tracker without upgrading an
+// older file on disk
+func ReadGlobalInstallationTracker() (*InstallationTracker, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	return loadTrackerFile(filepath.Join(configDir, installTrackerFileName))
+}
+
 // SaveGlobalInstallationTracker saves the global installation tracker
 func SaveGlobalInstallationTracker(tracker *InstallationTracker) error {
 	configDir, err := GetConfigDir()
@@ -129,12 +218,191 @@ func SaveGlobalInstallationTracker(tracker *InstallationTracker) error {
 	return SaveInstallationTracker(configDir, tracker)
 }
 
-// LoadProjectInstallationTracker loads the installation tracker (same global location)
-func LoadProjectInstallationTracker() (*InstallationTracker, error) {
-	return LoadGlobalInstallationTracker()
+// UpdateGlobalInstallationTracker runs fn inside a locked transaction on the global installation tracker
+func UpdateGlobalInstallationTracker(fn func(*InstallationTracker) error) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	return UpdateInstallationTracker(configDir, fn)
+}
+
+// GlobalInstallationTrackerPath returns the path of the global installation tracker
+func GlobalInstallationTrackerPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, installTrackerFileName), nil
+}
+
+// MigrateGlobalInstallationTracker upgrades the global tracker file to the current schema version
+func MigrateGlobalInstallationTracker() (*MigrationResult, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	return migrateTrackerFile(filepath.Join(configDir, installTrackerFileName), filepath.Join(configDir, installTrackerLockFileName))
+}
+
+// MigrateProjectInstallationTracker upgrades the in-project tracker of projectDir to the current schema version
+func MigrateProjectInstallationTracker(projectDir string) (*MigrationResult, error) {
+	trackerDir := filepath.Join(projectDir, ProjectTrackerDir)
+	return migrateTrackerFile(ProjectInstallationTrackerPath(projectDir), filepath.Join(trackerDir, projectTrackerLockFileName))
 }
 
-// SaveProjectInstallationTracker saves the installation tracker (same global location)
-func SaveProjectInstallationTracker(tracker *InstallationTracker) error {
-	return SaveGlobalInstallationTracker(tracker)
+// migrateTrackerFile runs the tracker migrations on trackerPath while holding its lock
+func migrateTrackerFile(trackerPath, lockPath string) (*MigrationResult, error) {
+	if _, err := os.Stat(trackerPath); os.IsNotExist(err) {
+		return &MigrationResult{Path: trackerPath, FromVersion: InstallationTrackerVersion, ToVersion: InstallationTrackerVersion}, nil
+	}
+
+	var result *MigrationResult
+	err := withFileLock(lockPath, func() error {
+		var err error
+		result, err = InstallationTrackerSchema.MigrateFile(trackerPath)
+		return err
+	})
+	return result, err
+}
+
+// ProjectInstallationTrackerPath returns the path of the in-project tracker for projectDir
+func ProjectInstallationTrackerPath(projectDir string) string {
+	return filepath.Join(projectDir, ProjectTrackerDir, projectTrackerFileName)
+}
+
+// HasProjectInstallationTracker reports whether projectDir keeps its own installation records
+func HasProjectInstallationTracker(projectDir string) bool {
+	if projectDir == "" {
+		return false
+	}
+	_, err := os.Stat(ProjectInstallationTrackerPath(projectDir))
+	return err == nil
+}
+
+// LoadProjectInstallationTracker loads the tracker stored in <projectDir>/.airuler/installs.yaml.
+// Paths are stored relative to the project and returned as absolute paths under projectDir.
+func LoadProjectInstallationTracker(projectDir string) (*InstallationTracker, error) {
+	if projectDir == "" {
+		return nil, fmt.Errorf("project directory cannot be empty")
+	}
+
+	absProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	trackerPath := ProjectInstallationTrackerPath(absProjectDir)
+	lockPath := filepath.Join(absProjectDir, ProjectTrackerDir, projectTrackerLockFileName)
+	if err := upgradeTrackerFile(trackerPath, lockPath); err != nil {
+		return nil, err
+	}
+
+	return ReadProjectInstallationTracker(absProjectDir)
+}
+
+// ReadProjectInstallationTracker loads the tracker of projectDir like
+// LoadProjectInstallationTracker, without upgrading an older file on disk
+func ReadProjectInstallationTracker(projectDir string) (*InstallationTracker, error) {
+	if projectDir == "" {
+		return nil, fmt.Errorf("project directory cannot be empty")
+	}
+
+	absProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	tracker, err := loadTrackerFile(ProjectInstallationTrackerPath(absProjectDir))
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.resolveProjectPaths(absProjectDir)
+	return tracker, nil
+}
+
+// SaveProjectInstallationTracker saves the tracker to <projectDir>/.airuler/installs.yaml
+// with paths made relative to the project, so the file can be committed alongside it
+func SaveProjectInstallationTracker(projectDir string, tracker *InstallationTracker) error {
+	return UpdateProjectInstallationTracker(projectDir, func(t *InstallationTracker) error {
+		t.Installations = append([]InstallationRecord{}, tracker.Installations...)
+		return nil
+	})
+}
+
+// UpdateProjectInstallationTracker runs fn inside a locked transaction on the in-project tracker.
+// fn sees absolute paths; they are converted back to project-relative paths when saving.
+func UpdateProjectInstallationTracker(projectDir string, fn func(*InstallationTracker) error) error {
+	if projectDir == "" {
+		return fmt.Errorf("project directory cannot be empty")
+	}
+
+	absProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	trackerDir := filepath.Join(absProjectDir, ProjectTrackerDir)
+	if err := os.MkdirAll(trackerDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Keep the lock file out of version control
+	gitignorePath := filepath.Join(trackerDir, ".gitignore")
+	if _, err := os.Stat(gitignorePath); os.IsNotExist(err) {
+		if err := os.WriteFile(gitignorePath, []byte(projectTrackerGitignoreContent), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", gitignorePath, err)
+		}
+	}
+
+	trackerPath := ProjectInstallationTrackerPath(absProjectDir)
+	return withFileLock(filepath.Join(trackerDir, projectTrackerLockFileName), func() error {
+		if _, err := InstallationTrackerSchema.MigrateFile(trackerPath); err != nil {
+			return err
+		}
+
+		tracker, err := loadTrackerFile(trackerPath)
+		if err != nil {
+			return err
+		}
+		tracker.resolveProjectPaths(absProjectDir)
+
+		if err := fn(tracker); err != nil {
+			return err
+		}
+
+		tracker.relativizeProjectPaths(absProjectDir)
+		return writeTrackerFile(trackerPath, tracker)
+	})
+}
+
+// resolveProjectPaths converts project-relative record paths to absolute paths under projectDir
+func (t *InstallationTracker) resolveProjectPaths(projectDir string) {
+	for i := range t.Installations {
+		record := &t.Installations[i]
+		record.Global = false
+		record.ProjectPath = projectDir
+		if record.FilePath != "" && !filepath.IsAbs(record.FilePath) {
+			record.FilePath = filepath.Join(projectDir, filepath.FromSlash(record.FilePath))
+		}
+	}
+}
+
+// relativizeProjectPaths converts absolute record paths under projectDir to portable relative paths
+func (t *InstallationTracker) relativizeProjectPaths(projectDir string) {
+	for i := range t.Installations {
+		record := &t.Installations[i]
+		record.Global = false
+		record.ProjectPath = "."
+		if filepath.IsAbs(record.FilePath) {
+			rel, err := filepath.Rel(projectDir, record.FilePath)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				record.FilePath = filepath.ToSlash(rel)
+			}
+		}
+	}
 }
@@ -4,8 +4,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -209,6 +213,96 @@ func TestSaveInstallationTracker(t *testing.T) {
 	})
 }
 
+func TestUpdateInstallationTracker(t *testing.T) {
+	t.Run("applies changes and persists them", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		err := UpdateInstallationTracker(tempDir, func(tracker *InstallationTracker) error {
+			tracker.AddInstallation(InstallationRecord{
+				Target:   "cursor",
+				Rule:     "test-rule",
+				Global:   true,
+				FilePath: "/test/file.mdc",
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateInstallationTracker error = %v, want nil", err)
+		}
+
+		tracker, err := LoadInstallationTracker(tempDir)
+		if err != nil {
+			t.Fatalf("LoadInstallationTracker error = %v", err)
+		}
+		if len(tracker.Installations) != 1 {
+			t.Errorf("Tracker has %d installations, want 1", len(tracker.Installations))
+		}
+	})
+
+	t.Run("does not save when callback fails", func(t *testing.T) {
+		tempDir := t.TempDir()
+		callbackErr := errors.New("callback failed")
+
+		err := UpdateInstallationTracker(tempDir, func(tracker *InstallationTracker) error {
+			tracker.AddInstallation(InstallationRecord{Target: "cursor", Rule: "test-rule"})
+			return callbackErr
+		})
+		if !errors.Is(err, callbackErr) {
+			t.Errorf("UpdateInstallationTracker error = %v, want %v", err, callbackErr)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempDir, installTrackerFileName)); !os.IsNotExist(err) {
+			t.Error("Tracker file should not be written when callback fails")
+		}
+	})
+
+	t.Run("concurrent updates are not lost", func(t *testing.T) {
+		tempDir := t.TempDir()
+		const workers = 20
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- UpdateInstallationTracker(tempDir, func(tracker *InstallationTracker) error {
+					tracker.AddInstallation(InstallationRecord{
+						Target:   "cursor",
+						Rule:     fmt.Sprintf("rule-%d", i),
+						Global:   true,
+						FilePath: fmt.Sprintf("/test/rule-%d.mdc", i),
+					})
+					return nil
+				})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("UpdateInstallationTracker error = %v", err)
+			}
+		}
+
+		tracker, err := LoadInstallationTracker(tempDir)
+		if err != nil {
+			t.Fatalf("LoadInstallationTracker error = %v", err)
+		}
+		if len(tracker.Installations) != workers {
+			t.Errorf("Tracker has %d installations, want %d", len(tracker.Installations), workers)
+		}
+	})
+
+	t.Run("returns error for empty directory", func(t *testing.T) {
+		err := UpdateInstallationTracker("", func(_ *InstallationTracker) error { return nil })
+		if err == nil {
+			t.Error("UpdateInstallationTracker with empty directory should return error")
+		}
+	})
+}
+
 func TestInstallationTracker_AddInstallation(t *testing.T) {
 	t.Run("adds new installation", func(t *testing.T) {
 		tracker := &InstallationTracker{}
@@ -488,33 +582,135 @@ func TestGlobalInstallationTrackerFunctions(t *testing.T) {
 
 func TestProjectInstallationTrackerFunctions(t *testing.T) {
 	t.Run("LoadProjectInstallationTracker", func(t *testing.T) {
-		tracker, err := LoadProjectInstallationTracker()
+		projectDir := t.TempDir()
+
+		tracker, err := LoadProjectInstallationTracker(projectDir)
 		if err != nil {
 			t.Errorf("LoadProjectInstallationTracker() error = %v, want nil", err)
 		}
 		if tracker == nil {
 			t.Error("LoadProjectInstallationTracker() returned nil tracker")
 		}
+		if HasProjectInstallationTracker(projectDir) {
+			t.Error("HasProjectInstallationTracker() = true before any save")
+		}
 	})
 
 	t.Run("SaveProjectInstallationTracker", func(t *testing.T) {
+		projectDir := t.TempDir()
 		tracker := &InstallationTracker{
 			Installations: []InstallationRecord{
 				{
 					Target:      "project-test",
 					Rule:        "project-rule",
 					Global:      false,
-					ProjectPath: "/test/project",
+					ProjectPath: projectDir,
 					Mode:        "normal",
-					FilePath:    "/test/project/file",
+					FilePath:    filepath.Join(projectDir, ".cursor", "rules", "file.mdc"),
 					InstalledAt: time.Now(),
 				},
 			},
 		}
 
-		err := SaveProjectInstallationTracker(tracker)
+		err := SaveProjectInstallationTracker(projectDir, tracker)
 		if err != nil {
 			t.Errorf("SaveProjectInstallationTracker() error = %v, want nil", err)
 		}
+		if !HasProjectInstallationTracker(projectDir) {
+			t.Error("HasProjectInstallationTracker() = false after save")
+		}
+		if _, err := os.Stat(filepath.Join(projectDir, ProjectTrackerDir, ".gitignore")); err != nil {
+			t.Errorf("expected .gitignore in tracker dir: %v", err)
+		}
+	})
+
+	t.Run("stores paths relative to the project", func(t *testing.T) {
+		projectDir := t.TempDir()
+		filePath := filepath.Join(projectDir, ".cursor", "rules", "file.mdc")
+		dottedPath := filepath.Join(projectDir, "..cursorrules")
+		outsidePath := filepath.Join(filepath.Dir(projectDir), "outside.md")
+
+		err := UpdateProjectInstallationTracker(projectDir, func(tracker *InstallationTracker) error {
+			tracker.AddInstallation(InstallationRecord{
+				Target:      "cursor",
+				Rule:        "file",
+				ProjectPath: projectDir,
+				FilePath:    filePath,
+			})
+			tracker.AddInstallation(InstallationRecord{Target: "cline", Rule: "dotted", ProjectPath: projectDir, FilePath: dottedPath})
+			tracker.AddInstallation(InstallationRecord{Target: "roo", Rule: "outside", ProjectPath: projectDir, FilePath: outsidePath})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateProjectInstallationTracker() error = %v", err)
+		}
+
+		data, err := os.ReadFile(ProjectInstallationTrackerPath(projectDir))
+		if err != nil {
+			t.Fatalf("failed to read project tracker: %v", err)
+		}
+		content := string(data)
+		if strings.Contains(content, projectDir) {
+			t.Errorf("project tracker contains absolute path:\n%s", content)
+		}
+		for _, want := range []string{".cursor/rules/file.mdc", "file_path: ..cursorrules", outsidePath} {
+			if !strings.Contains(content, want) {
+				t.Errorf("project tracker missing file path %q:\n%s", want, content)
+			}
+		}
+	})
+
+	t.Run("resolves relative paths against a moved project", func(t *testing.T) {
+		originalDir := t.TempDir()
+		err := UpdateProjectInstallationTracker(originalDir, func(tracker *InstallationTracker) error {
+			tracker.AddInstallation(InstallationRecord{
+				Target:      "claude",
+				Rule:        "memory",
+				ProjectPath: originalDir,
+				Mode:        "memory",
+				FilePath:    filepath.Join(originalDir, "CLAUDE.md"),
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateProjectInstallationTracker() error = %v", err)
+		}
+
+		// Simulate a teammate cloning the project somewhere else
+		clonedDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(clonedDir, ProjectTrackerDir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(ProjectInstallationTrackerPath(originalDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(ProjectInstallationTrackerPath(clonedDir), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		tracker, err := LoadProjectInstallationTracker(clonedDir)
+		if err != nil {
+			t.Fatalf("LoadProjectInstallationTracker() error = %v", err)
+		}
+		if len(tracker.Installations) != 1 {
+			t.Fatalf("got %d installations, want 1", len(tracker.Installations))
+		}
+		record := tracker.Installations[0]
+		if record.ProjectPath != clonedDir {
+			t.Errorf("ProjectPath = %q, want %q", record.ProjectPath, clonedDir)
+		}
+		if record.FilePath != filepath.Join(clonedDir, "CLAUDE.md") {
+			t.Errorf("FilePath = %q, want %q", record.FilePath, filepath.Join(clonedDir, "CLAUDE.md"))
+		}
+		if record.Global {
+			t.Error("project record should not be global")
+		}
+	})
+
+	t.Run("empty project dir", func(t *testing.T) {
+		if _, err := LoadProjectInstallationTracker(""); err == nil {
+			t.Error("LoadProjectInstallationTracker(\"\") expected error")
+		}
 	})
 }
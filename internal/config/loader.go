@@ -6,7 +6,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,30 +20,14 @@ import (
 func LoadVendorConfigs(templateDir string, projectConfig *Config) (*MergedVendorConfigs, error) {
 	vendorConfigs := make(map[string]VendorConfig)
 
-	// Get vendor directories
-	vendorsDir := filepath.Join(templateDir, "vendors")
-	if _, err := os.Stat(vendorsDir); os.IsNotExist(err) {
-		// No vendors directory, return empty config
-		return &MergedVendorConfigs{
-			VendorConfigs: vendorConfigs,
-			ProjectConfig: projectConfig,
-		}, nil
-	}
-
-	// Read vendor directories
-	vendorDirs, err := os.ReadDir(vendorsDir)
+	vendorRoots, err := vendorRootDirs(templateDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read vendors directory: %w", err)
+		return nil, err
 	}
 
 	// Load configuration from each vendor
-	for _, vendorDir := range vendorDirs {
-		if !vendorDir.IsDir() {
-			continue
-		}
-
-		vendorName := vendorDir.Name()
-		vendorConfigPath := filepath.Join(vendorsDir, vendorName, "airuler.yaml")
+	for vendorName, vendorRoot := range vendorRoots {
+		vendorConfigPath := filepath.Join(vendorRoot, "airuler.yaml")
 
 		// Check if vendor config exists
 		if _, err := os.Stat(vendorConfigPath); os.IsNotExist(err) {
@@ -184,10 +172,28 @@ func mergeTargetConfig(base, override TargetConfig) TargetConfig {
 	return merged
 }
 
-// mergeCompilationConfig merges compilation configurations
-func mergeCompilationConfig(base, _ CompilationConfig) CompilationConfig {
-	// Currently no fields to merge, but keeping function for future extensibility
-	return base
+// mergeCompilationConfig merges compilation configurations. Lists set in the override replace
+// the base lists rather than extending them.
+func mergeCompilationConfig(base, override CompilationConfig) CompilationConfig {
+	merged := base
+
+	if override.Include != nil {
+		merged.Include = override.Include
+	}
+	if override.Exclude != nil {
+		merged.Exclude = override.Exclude
+	}
+	if override.Targets != nil {
+		merged.Targets = override.Targets
+	}
+	if override.Prefix != nil {
+		merged.Prefix = override.Prefix
+	}
+	if override.DefaultMode != "" {
+		merged.DefaultMode = override.DefaultMode
+	}
+
+	return merged
 }
 
 // ResolveTemplateContext resolves the configuration context for a specific template
@@ -233,6 +239,7 @@ func (m *MergedVendorConfigs) GetVendorManifest(vendorName string) (VendorManife
 func (m *MergedVendorConfigs) ValidateVendorConfigs() []error {
 	var errors []error
 
+	validModes := []string{"memory", "command", "both"}
 	for vendorName, config := range m.VendorConfigs {
 		// Validate vendor manifest
 		if config.Vendor.Name == "" && len(config.TemplateDefaults) > 0 {
@@ -242,7 +249,6 @@ func (m *MergedVendorConfigs) ValidateVendorConfigs() []error {
 		// Validate target configurations
 		for target, targetConfig := range config.Targets {
 			if targetConfig.DefaultMode != "" {
-				validModes := []string{"memory", "command", "both"}
 				isValid := false
 				for _, mode := range validModes {
 					if targetConfig.DefaultMode == mode {
@@ -256,6 +262,15 @@ func (m *MergedVendorConfigs) ValidateVendorConfigs() []error {
 			}
 		}
 
+		if mode := config.Compilation.DefaultMode; mode != "" && !slices.Contains(validModes, mode) {
+			errors = append(errors, fmt.Errorf("vendor %s has invalid compilation default_mode '%s'", vendorName, mode))
+		}
+		for _, pattern := range append(slices.Clone(config.Compilation.Include), config.Compilation.Exclude...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errors = append(errors, fmt.Errorf("vendor %s has invalid compilation pattern '%s'", vendorName, pattern))
+			}
+		}
+
 		// Validate template defaults don't contain reserved keys
 		reservedKeys := []string{"Target", "Name"}
 		for _, key := range reservedKeys {
@@ -298,24 +313,60 @@ func GetIncludedVendors(projectConfig *Config, availableVendors []string) []stri
 	return included
 }
 
-// GetAvailableVendors returns a list of vendor names found in the vendors directory
+// GetAvailableVendors returns a list of vendor names found in the vendors directory or the lock file
 func GetAvailableVendors(templateDir string) ([]string, error) {
-	vendorsDir := filepath.Join(templateDir, "vendors")
+	vendorRoots, err := vendorRootDirs(templateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	vendors := make([]string, 0, len(vendorRoots))
+	for name := range vendorRoots {
+		vendors = append(vendors, name)
+	}
+	sort.Strings(vendors)
+
+	return vendors, nil
+}
+
+// vendorRootDirs maps vendor names to the directories holding their templates/ and airuler.yaml.
+// Directories under vendors/ are vendors by name; lock file entries add vendors that live in a
+// subdirectory of a shared clone. Hidden directories such as vendors/.repos are skipped.
+func vendorRootDirs(templateDir string) (map[string]string, error) {
+	roots := make(map[string]string)
+
+	vendorsDir := filepath.Join(templateDir, VendorsDir)
 	if _, err := os.Stat(vendorsDir); os.IsNotExist(err) {
-		return []string{}, nil
+		return roots, nil
 	}
 
 	vendorDirs, err := os.ReadDir(vendorsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read vendors directory: %w", err)
 	}
-
-	var vendors []string
 	for _, vendorDir := range vendorDirs {
-		if vendorDir.IsDir() {
-			vendors = append(vendors, vendorDir.Name())
+		if strings.HasPrefix(vendorDir.Name(), ".") {
+			continue
+		}
+		// Local vendors may be symlinks to a directory next to the project
+		if info, err := os.Stat(filepath.Join(vendorsDir, vendorDir.Name())); err == nil && info.IsDir() {
+			roots[vendorDir.Name()] = filepath.Join(vendorsDir, vendorDir.Name())
 		}
 	}
 
-	return vendors, nil
+	lockFile, err := ReadLockFile(filepath.Join(templateDir, LockFileName))
+	if err != nil {
+		return nil, err
+	}
+	for name, lock := range lockFile.Vendors {
+		if lock.Path == "" && lock.Checkout == "" {
+			continue
+		}
+		root := filepath.Join(templateDir, lock.RootPath(name))
+		if _, err := os.Stat(root); err == nil {
+			roots[name] = root
+		}
+	}
+
+	return roots, nil
 }
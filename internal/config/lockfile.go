@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// LockFileName is the name of the vendor lock file in a template directory
+	LockFileName = "airuler.lock"
+	// VendorsDir is the directory vendor repositories are cloned into
+	VendorsDir = "vendors"
+	// SharedCheckoutDir holds clones shared by vendors that use a subdirectory of a repository
+	SharedCheckoutDir = ".repos"
+)
+
+// Vendor source types recorded in the lock file
+const (
+	SourceGit     = "git"
+	SourceLocal   = "local"
+	SourceArchive = "archive"
+)
+
+// SourceType returns the vendor's source type; entries without one are git repositories
+func (l VendorLock) SourceType() string {
+	if l.Source == "" {
+		return SourceGit
+	}
+	return l.Source
+}
+
+// Version identifies the locked content: the commit of a git vendor, or the checksum of
+// an archive or copied directory. Symlinked local vendors have no version.
+func (l VendorLock) Version() string {
+	if l.Commit != "" {
+		return l.Commit
+	}
+	return l.SHA256
+}
+
+// CheckoutPath returns the directory, relative to the template directory, holding the vendor's clone
+func (l VendorLock) CheckoutPath(name string) string {
+	if l.Checkout != "" {
+		return filepath.Join(VendorsDir, filepath.FromSlash(l.Checkout))
+	}
+	return filepath.Join(VendorsDir, name)
+}
+
+// RootPath returns the directory holding the vendor's templates/ and airuler.yaml
+func (l VendorLock) RootPath(name string) string {
+	if l.Path == "" {
+		return l.CheckoutPath(name)
+	}
+	return filepath.Join(l.CheckoutPath(name), filepath.FromSlash(l.Path))
+}
+
+// CleanVendorPath normalizes a vendor subdirectory and rejects paths that escape the repository
+func CleanVendorPath(path string) (string, error) {
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+	if cleaned == "." {
+		return "", nil
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("vendor path %q must be relative to the repository root", path)
+	}
+	return cleaned, nil
+}
+
+// NewLockFile returns an empty lock file at the current schema version
+func NewLockFile() *LockFile {
+	return &LockFile{
+		Version: LockFileVersion,
+		Vendors: make(map[string]VendorLock),
+	}
+}
+
+// LoadLockFile reads the lock file at path, upgrading older versions on disk first.
+// A missing file yields an empty lock file.
+func LoadLockFile(path string) (*LockFile, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return NewLockFile(), nil
+	}
+
+	if _, err := LockFileSchema.MigrateFile(path); err != nil {
+		return nil, err
+	}
+
+	return ReadLockFile(path)
+}
+
+// ReadLockFile reads the lock file at path like LoadLockFile, but upgrades older versions in
+// memory only and never writes to disk
+func ReadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewLockFile(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	if data, _, _, err = LockFileSchema.MigrateData(data); err != nil {
+		return nil, err
+	}
+
+	lockFile := NewLockFile()
+	if err := yaml.Unmarshal(data, lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+	if lockFile.Vendors == nil {
+		lockFile.Vendors = make(map[string]VendorLock)
+	}
+
+	return lockFile, nil
+}
+
+// SaveLockFile writes the lock file to path at the current schema version
+func SaveLockFile(path string, lockFile *LockFile) error {
+	lockFile.Version = LockFileVersion
+
+	data, err := yaml.Marshal(lockFile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock file: %w", err)
+	}
+
+	return writeFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCleanVendorPath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: ""},
+		{input: ".", want: ""},
+		{input: "packs/python", want: "packs/python"},
+		{input: "packs//python/", want: "packs/python"},
+		{input: "./packs/../packs/go", want: "packs/go"},
+		{input: "../outside", wantErr: true},
+		{input: "packs/../../outside", wantErr: true},
+		{input: "/etc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := CleanVendorPath(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("CleanVendorPath(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CleanVendorPath(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("CleanVendorPath(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVendorLock_Paths(t *testing.T) {
+	plain := VendorLock{URL: "https://github.com/org/rules"}
+	if got := plain.RootPath("rules"); got != filepath.Join("vendors", "rules") {
+		t.Errorf("RootPath() = %s, want vendors/rules", got)
+	}
+
+	pack := VendorLock{URL: "https://github.com/org/packs", Path: "packs/python", Checkout: ".repos/github-com-org-packs"}
+	if got := pack.CheckoutPath("python"); got != filepath.Join("vendors", ".repos", "github-com-org-packs") {
+		t.Errorf("CheckoutPath() = %s", got)
+	}
+	if got := pack.RootPath("python"); got != filepath.Join("vendors", ".repos", "github-com-org-packs", "packs", "python") {
+		t.Errorf("RootPath() = %s", got)
+	}
+}
+
+func TestGetAvailableVendors_Subdirectories(t *testing.T) {
+	templateDir := t.TempDir()
+	checkout := filepath.Join(templateDir, "vendors", ".repos", "github-com-org-packs")
+	for _, dir := range []string{
+		filepath.Join(templateDir, "vendors", "plain", "templates"),
+		filepath.Join(checkout, "packs", "python", "templates"),
+		filepath.Join(checkout, "packs", "go", "templates"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(checkout, "packs", "python", "airuler.yaml"),
+		[]byte("vendor:\n  name: python-pack\n"), 0600); err != nil {
+		t.Fatalf("Failed to write vendor config: %v", err)
+	}
+
+	lockFile := NewLockFile()
+	lockFile.Vendors["plain"] = VendorLock{URL: "https://github.com/org/plain", Commit: "abc"}
+	for _, name := range []string{"python", "go"} {
+		lockFile.Vendors[name] = VendorLock{
+			URL:      "https://github.com/org/packs",
+			Path:     "packs/" + name,
+			Checkout: ".repos/github-com-org-packs",
+			Commit:   "def",
+		}
+	}
+	if err := SaveLockFile(filepath.Join(templateDir, LockFileName), lockFile); err != nil {
+		t.Fatalf("SaveLockFile() error = %v", err)
+	}
+
+	vendors, err := GetAvailableVendors(templateDir)
+	if err != nil {
+		t.Fatalf("GetAvailableVendors() error = %v", err)
+	}
+	if want := []string{"go", "plain", "python"}; !reflect.DeepEqual(vendors, want) {
+		t.Errorf("GetAvailableVendors() = %v, want %v", vendors, want)
+	}
+
+	merged, err := LoadVendorConfigs(templateDir, NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("LoadVendorConfigs() error = %v", err)
+	}
+	if got := merged.VendorConfigs["python"].Vendor.Name; got != "python-pack" {
+		t.Errorf("python vendor config name = %q, want python-pack (read from the subdirectory)", got)
+	}
+	if _, ok := merged.VendorConfigs[".repos"]; ok {
+		t.Error("the shared clone directory should not be treated as a vendor")
+	}
+}
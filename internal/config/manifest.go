@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// CompileManifestFileName is the name of the provenance manifest written into the compiled directory
+const CompileManifestFileName = ".airuler-manifest.yaml"
+
+// Provenance describes where a compiled or installed rule came from
+type Provenance struct {
+	Source         string `yaml:"source,omitempty"`          // "local" or vendor name
+	VendorCommit   string `yaml:"vendor_commit,omitempty"`   // commit from airuler.lock for vendor templates
+	TemplateCommit string `yaml:"template_commit,omitempty"` // commit of the template repository
+	TemplateHash   string `yaml:"template_hash,omitempty"`   // sha256 of the template source
+	AirulerVersion string `yaml:"airuler_version,omitempty"` // airuler version that compiled the rule
+}
+
+// CompiledFile records the provenance of a single compiled output file
+type CompiledFile struct {
+	File       string     `yaml:"file"`
+	Template   string     `yaml:"template"`
+	Provenance Provenance `yaml:",inline"`
+}
+
+// CompileManifest records the provenance of every file in the compiled directory, keyed by target
+type CompileManifest struct {
+	CompiledAt time.Time                 `yaml:"compiled_at"`
+	Targets    map[string][]CompiledFile `yaml:"targets"`
+}
+
+// NewCompileManifest returns an empty compile manifest
+func NewCompileManifest() *CompileManifest {
+	return &CompileManifest{
+		CompiledAt: time.Now(),
+		Targets:    make(map[string][]CompiledFile),
+	}
+}
+
+// Add records a compiled file for target
+func (m *CompileManifest) Add(target string, file CompiledFile) {
+	m.Targets[target] = append(m.Targets[target], file)
+}
+
+// Lookup finds the compiled file for target matching either the output file name or the rule name
+func (m *CompileManifest) Lookup(target, rule string) (CompiledFile, bool) {
+	for _, file := range m.Targets[target] {
+		stem := strings.TrimSuffix(file.File, filepath.Ext(file.File))
+		if file.File == rule || stem == rule || file.Template == rule {
+			return file, true
+		}
+	}
+	return CompiledFile{}, false
+}
+
+// LoadCompileManifest reads the manifest from compiledDir. A missing manifest yields an empty one.
+func LoadCompileManifest(compiledDir string) (*CompileManifest, error) {
+	manifest := NewCompileManifest()
+	manifest.CompiledAt = time.Time{}
+
+	data, err := os.ReadFile(filepath.Join(compiledDir, CompileManifestFileName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compile manifest: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse compile manifest: %w", err)
+	}
+	if manifest.Targets == nil {
+		manifest.Targets = make(map[string][]CompiledFile)
+	}
+
+	return manifest, nil
+}
+
+// SaveCompileManifest writes the manifest into compiledDir
+func SaveCompileManifest(compiledDir string, manifest *CompileManifest) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal compile manifest: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(compiledDir, CompileManifestFileName), data, 0600)
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"testing"
+)
+
+func TestCompileManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	manifest := NewCompileManifest()
+	manifest.Add("cursor", CompiledFile{
+		File:     "my-rule.mdc",
+		Template: "my-rule",
+		Provenance: Provenance{
+			Source:       "acme-rules",
+			VendorCommit: "abc123",
+			TemplateHash: "deadbeef",
+		},
+	})
+	manifest.Add("claude", CompiledFile{File: "CLAUDE.md", Template: "a,b", Provenance: Provenance{Source: "local"}})
+
+	if err := SaveCompileManifest(dir, manifest); err != nil {
+		t.Fatalf("SaveCompileManifest() error = %v", err)
+	}
+
+	loaded, err := LoadCompileManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadCompileManifest() error = %v", err)
+	}
+
+	tests := []struct {
+		target string
+		rule   string
+		found  bool
+		source string
+	}{
+		{"cursor", "my-rule", true, "acme-rules"},
+		{"cursor", "my-rule.mdc", true, "acme-rules"},
+		{"claude", "CLAUDE", true, "local"},
+		{"claude", "my-rule", false, ""},
+		{"cline", "my-rule", false, ""},
+	}
+
+	for _, tt := range tests {
+		file, found := loaded.Lookup(tt.target, tt.rule)
+		if found != tt.found {
+			t.Errorf("Lookup(%q, %q) found = %v, want %v", tt.target, tt.rule, found, tt.found)
+			continue
+		}
+		if found && file.Provenance.Source != tt.source {
+			t.Errorf("Lookup(%q, %q) source = %q, want %q", tt.target, tt.rule, file.Provenance.Source, tt.source)
+		}
+	}
+
+	empty, err := LoadCompileManifest(t.TempDir())
+	if err != nil || len(empty.Targets) != 0 {
+		t.Errorf("LoadCompileManifest() on missing manifest = %+v, %v", empty, err)
+	}
+}
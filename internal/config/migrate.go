@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// InstallationTrackerVersion is the current schema version of installation tracker files
+	InstallationTrackerVersion = 2
+	// LockFileVersion is the current schema version of airuler.lock
+	LockFileVersion = 2
+)
+
+// Migration upgrades a raw YAML document from version From to From+1
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// Schema describes a versioned airuler file and the migrations that lead to its current version
+type Schema struct {
+	Name       string
+	Version    int
+	Migrations []Migration
+	newValue   func() interface{}
+}
+
+// InstallationTrackerSchema describes installation tracker files (global and in-project)
+var InstallationTrackerSchema = Schema{
+	Name:    "installation tracker",
+	Version: InstallationTrackerVersion,
+	Migrations: []Migration{
+		{
+			From:        0,
+			Description: "add schema version",
+			Apply:       func(map[string]interface{}) error { return nil },
+		},
+		{
+			// Records gain optional provenance fields; older airuler versions must not rewrite them
+			From:        1,
+			Description: "add installation provenance",
+			Apply:       func(map[string]interface{}) error { return nil },
+		},
+	},
+	newValue: func() interface{} { return &InstallationTracker{} },
+}
+
+// LockFileSchema describes airuler.lock files
+var LockFileSchema = Schema{
+	Name:    "lock file",
+	Version: LockFileVersion,
+	Migrations: []Migration{
+		{
+			From:        0,
+			Description: "add schema version",
+			Apply:       func(map[string]interface{}) error { return nil },
+		},
+		{
+			// Vendors gain optional ref, path, source, tree hash and requirement fields that
+			// older airuler versions must not rewrite. Entries without them keep working:
+			// no tree hash is reported as unverified and no requirements as none.
+			From:        1,
+			Description: "add vendor refs, sources, tree hashes and requirements",
+			Apply:       func(map[string]interface{}) error { return nil },
+		},
+	},
+	newValue: func() interface{} { return &LockFile{} },
+}
+
+// MigrationResult describes the outcome of migrating a single file
+type MigrationResult struct {
+	Path        string
+	FromVersion int
+	ToVersion   int
+	BackupPath  string
+	Migrated    bool
+}
+
+// MigrateData upgrades a YAML document to the schema's current version.
+// It returns the upgraded document, the version it was found at, and whether anything changed.
+func (s Schema) MigrateData(data []byte) ([]byte, int, bool, error) {
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to parse %s: %w", s.Name, err)
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	from, err := documentVersion(doc)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("invalid %s: %w", s.Name, err)
+	}
+
+	if from > s.Version {
+		return nil, from, false, fmt.Errorf(
+			"%s has version %d but this airuler supports up to version %d; please upgrade airuler",
+			s.Name, from, s.Version)
+	}
+	if from == s.Version {
+		return data, from, false, nil
+	}
+
+	for version := from; version < s.Version; version++ {
+		migration, ok := s.findMigration(version)
+		if !ok {
+			return nil, from, false, fmt.Errorf("no migration for %s from version %d", s.Name, version)
+		}
+		if err := migration.Apply(doc); err != nil {
+			return nil, from, false, fmt.Errorf("failed to migrate %s from version %d (%s): %w",
+				s.Name, version, migration.Description, err)
+		}
+	}
+	doc["version"] = s.Version
+
+	// Round-trip through the typed structure to keep a stable field order
+	raw, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, from, false, fmt.Errorf("failed to marshal migrated %s: %w", s.Name, err)
+	}
+	value := s.newValue()
+	if err := yaml.Unmarshal(raw, value); err != nil {
+		return nil, from, false, fmt.Errorf("failed to decode migrated %s: %w", s.Name, err)
+	}
+	migrated, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, from, false, fmt.Errorf("failed to marshal migrated %s: %w", s.Name, err)
+	}
+
+	return migrated, from, true, nil
+}
+
+// FileVersion returns the schema version recorded in the file at path.
+// Missing files report the current version since there is nothing to migrate.
+func (s Schema) FileVersion(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s.Version, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", s.Name, err)
+	}
+
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", s.Name, err)
+	}
+	return documentVersion(doc)
+}
+
+// MigrateFile upgrades the file at path in place, saving the original next to it first.
+// Callers that share the file with other processes must hold its lock.
+func (s Schema) MigrateFile(path string) (*MigrationResult, error) {
+	result := &MigrationResult{Path: path, ToVersion: s.Version}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		result.FromVersion = s.Version
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.Name, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", s.Name, err)
+	}
+
+	migrated, from, changed, err := s.MigrateData(data)
+	result.FromVersion = from
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return result, nil
+	}
+
+	// Keep the first backup of each version; never overwrite it with a later run
+	backupPath := fmt.Sprintf("%s.v%d.backup", path, from)
+	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+		if err := os.WriteFile(backupPath, data, info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", s.Name, err)
+		}
+	}
+
+	if err := writeFileAtomic(path, migrated, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write migrated %s: %w", s.Name, err)
+	}
+
+	result.BackupPath = backupPath
+	result.Migrated = true
+	return result, nil
+}
+
+// findMigration returns the migration that upgrades from the given version
+func (s Schema) findMigration(from int) (Migration, bool) {
+	for _, migration := range s.Migrations {
+		if migration.From == from {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// documentVersion reads the version key of a raw document; files written before versioning report 0
+func documentVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	version, ok := raw.(int)
+	if !ok || version < 0 {
+		return 0, fmt.Errorf("version must be a non-negative integer, got %v", raw)
+	}
+	return version, nil
+}
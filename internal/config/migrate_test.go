@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const unversionedTracker = `installations:
+    - target: cursor
+      rule: my-rule
+      global: true
+      mode: ""
+      installed_at: 2024-01-01T00:00:00Z
+      file_path: /home/user/.cursor/rules/my-rule.mdc
+`
+
+func TestSchemaMigrateData(t *testing.T) {
+	t.Run("upgrades unversioned document", func(t *testing.T) {
+		migrated, from, changed, err := InstallationTrackerSchema.MigrateData([]byte(unversionedTracker))
+		if err != nil {
+			t.Fatalf("MigrateData() error = %v", err)
+		}
+		if from != 0 || !changed {
+			t.Errorf("MigrateData() from = %d, changed = %v; want 0, true", from, changed)
+		}
+
+		var tracker InstallationTracker
+		if err := yaml.Unmarshal(migrated, &tracker); err != nil {
+			t.Fatalf("failed to parse migrated tracker: %v", err)
+		}
+		if tracker.Version != InstallationTrackerVersion {
+			t.Errorf("Version = %d, want %d", tracker.Version, InstallationTrackerVersion)
+		}
+		if len(tracker.Installations) != 1 || tracker.Installations[0].Rule != "my-rule" {
+			t.Errorf("installations not preserved: %+v", tracker.Installations)
+		}
+	})
+
+	t.Run("current version is unchanged", func(t *testing.T) {
+		data := []byte(fmt.Sprintf("version: %d\ninstallations: []\n", InstallationTrackerVersion))
+		migrated, _, changed, err := InstallationTrackerSchema.MigrateData(data)
+		if err != nil {
+			t.Fatalf("MigrateData() error = %v", err)
+		}
+		if changed || string(migrated) != string(data) {
+			t.Error("MigrateData() should not change a current document")
+		}
+	})
+
+	t.Run("rejects newer version", func(t *testing.T) {
+		_, _, _, err := InstallationTrackerSchema.MigrateData([]byte("version: 99\n"))
+		if err == nil || !strings.Contains(err.Error(), "upgrade airuler") {
+			t.Errorf("MigrateData() error = %v, want newer version error", err)
+		}
+	})
+
+	t.Run("runs migrations in order", func(t *testing.T) {
+		var applied []int
+		schema := Schema{
+			Name:    "test",
+			Version: 2,
+			Migrations: []Migration{
+				{From: 1, Apply: func(doc map[string]interface{}) error {
+					applied = append(applied, 1)
+					doc["vendors"] = map[string]interface{}{}
+					return nil
+				}},
+				{From: 0, Apply: func(map[string]interface{}) error {
+					applied = append(applied, 0)
+					return nil
+				}},
+			},
+			newValue: func() interface{} { return &LockFile{} },
+		}
+
+		if _, _, _, err := schema.MigrateData([]byte("vendors: {}\n")); err != nil {
+			t.Fatalf("MigrateData() error = %v", err)
+		}
+		if len(applied) != 2 || applied[0] != 0 || applied[1] != 1 {
+			t.Errorf("migrations applied in order %v, want [0 1]", applied)
+		}
+	})
+
+	t.Run("failing migration is reported", func(t *testing.T) {
+		schema := Schema{
+			Name:    "test",
+			Version: 1,
+			Migrations: []Migration{
+				{From: 0, Description: "broken", Apply: func(map[string]interface{}) error {
+					return errors.New("boom")
+				}},
+			},
+			newValue: func() interface{} { return &LockFile{} },
+		}
+
+		if _, _, _, err := schema.MigrateData([]byte("vendors: {}\n")); err == nil || !strings.Contains(err.Error(), "broken") {
+			t.Errorf("MigrateData() error = %v, want migration failure", err)
+		}
+	})
+}
+
+func TestSchemaMigrateFile(t *testing.T) {
+	t.Run("writes backup and upgrades file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), installTrackerFileName)
+		if err := os.WriteFile(path, []byte(unversionedTracker), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := InstallationTrackerSchema.MigrateFile(path)
+		if err != nil {
+			t.Fatalf("MigrateFile() error = %v", err)
+		}
+		if !result.Migrated || result.FromVersion != 0 || result.ToVersion != InstallationTrackerVersion {
+			t.Errorf("unexpected result: %+v", result)
+		}
+
+		backup, err := os.ReadFile(result.BackupPath)
+		if err != nil {
+			t.Fatalf("failed to read backup: %v", err)
+		}
+		if string(backup) != unversionedTracker {
+			t.Error("backup does not contain the original content")
+		}
+
+		version, err := InstallationTrackerSchema.FileVersion(path)
+		if err != nil || version != InstallationTrackerVersion {
+			t.Errorf("FileVersion() = %d, %v; want %d", version, err, InstallationTrackerVersion)
+		}
+
+		// A second run is a no-op
+		result, err = InstallationTrackerSchema.MigrateFile(path)
+		if err != nil || result.Migrated {
+			t.Errorf("second MigrateFile() = %+v, %v; want no migration", result, err)
+		}
+	})
+
+	t.Run("missing file is not an error", func(t *testing.T) {
+		result, err := LockFileSchema.MigrateFile(filepath.Join(t.TempDir(), LockFileName))
+		if err != nil || result.Migrated {
+			t.Errorf("MigrateFile() = %+v, %v; want no migration", result, err)
+		}
+	})
+}
+
+func TestLoadInstallationTrackerUpgradesOnLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, installTrackerFileName)
+	if err := os.WriteFile(path, []byte(unversionedTracker), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker, err := LoadInstallationTracker(dir)
+	if err != nil {
+		t.Fatalf("LoadInstallationTracker() error = %v", err)
+	}
+	if tracker.Version != InstallationTrackerVersion || len(tracker.Installations) != 1 {
+		t.Errorf("unexpected tracker after load: %+v", tracker)
+	}
+	if _, err := os.Stat(path + ".v0.backup"); err != nil {
+		t.Errorf("expected backup of the original tracker: %v", err)
+	}
+}
+
+func TestLockFileLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	legacy := "vendors:\n    my-vendor:\n        url: https://example.com/repo.git\n        commit: abc123\n        fetched_at: 2024-01-01T00:00:00Z\n"
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	lockFile, err := LoadLockFile(path)
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+	if lockFile.Version != LockFileVersion {
+		t.Errorf("Version = %d, want %d", lockFile.Version, LockFileVersion)
+	}
+	if lockFile.Vendors["my-vendor"].Commit != "abc123" {
+		t.Errorf("vendor lock not preserved: %+v", lockFile.Vendors)
+	}
+	if _, err := os.Stat(path + ".v0.backup"); err != nil {
+		t.Errorf("expected lock file backup: %v", err)
+	}
+
+	lockFile.Vendors["other"] = VendorLock{URL: "https://example.com/other.git", Commit: "def456"}
+	if err := SaveLockFile(path, lockFile); err != nil {
+		t.Fatalf("SaveLockFile() error = %v", err)
+	}
+
+	reloaded, err := LoadLockFile(path)
+	if err != nil {
+		t.Fatalf("LoadLockFile() after save error = %v", err)
+	}
+	if len(reloaded.Vendors) != 2 {
+		t.Errorf("got %d vendors after reload, want 2", len(reloaded.Vendors))
+	}
+
+	missing, err := LoadLockFile(filepath.Join(t.TempDir(), LockFileName))
+	if err != nil || len(missing.Vendors) != 0 {
+		t.Errorf("LoadLockFile() on missing file = %+v, %v", missing, err)
+	}
+}
+
+func TestReadUpgradesInMemoryOnly(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, LockFileName)
+	legacyLock := "vendors:\n    my-vendor:\n        url: https://example.com/repo.git\n        commit: abc123\n"
+	trackerPath := ProjectInstallationTrackerPath(dir)
+	if err := os.MkdirAll(filepath.Dir(trackerPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{lockPath: legacyLock, trackerPath: unversionedTracker} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lockFile, err := ReadLockFile(lockPath)
+	if err != nil || lockFile.Version != LockFileVersion || lockFile.Vendors["my-vendor"].Commit != "abc123" {
+		t.Errorf("ReadLockFile() = %+v, %v", lockFile, err)
+	}
+	tracker, err := ReadProjectInstallationTracker(dir)
+	if err != nil || tracker.Version != InstallationTrackerVersion || len(tracker.Installations) != 1 {
+		t.Errorf("ReadProjectInstallationTracker() = %+v, %v", tracker, err)
+	}
+
+	// Neither file is rewritten or backed up
+	for path, content := range map[string]string{lockPath: legacyLock, trackerPath: unversionedTracker} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != content {
+			t.Errorf("%s was modified: %q, %v", path, data, err)
+		}
+		if _, err := os.Stat(path + ".v0.backup"); !os.IsNotExist(err) {
+			t.Errorf("%s should not be backed up: %v", path, err)
+		}
+	}
+}
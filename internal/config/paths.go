@@ -160,6 +160,40 @@ func UpdateLastTemplateDir(templateDir string) error {
 	return nil
 }
 
+// LoadGlobalConfig reads the global configuration, returning defaults when it does not exist
+func LoadGlobalConfig() (*Config, error) {
+	configFile, err := GetConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global config path: %w", err)
+	}
+
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return NewDefaultConfig(), nil
+	}
+	return LoadConfig(configFile)
+}
+
+// LoadConfig reads a project or global airuler.yaml, including its vendor overrides
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg := NewDefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if cfg.Defaults.IncludeVendors == nil {
+		cfg.Defaults.IncludeVendors = []string{}
+	}
+	if cfg.VendorOverrides == nil {
+		cfg.VendorOverrides = make(map[string]VendorConfig)
+	}
+
+	return cfg, nil
+}
+
 // GetLastTemplateDir retrieves the last template directory from global configuration
 func GetLastTemplateDir() (string, error) {
 	configPath, err := GetGlobalConfigPath()
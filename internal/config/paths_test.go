@@ -186,3 +186,77 @@ func TestHasGlobalConfig(t *testing.T) {
 		t.Error("HasGlobalConfig() returned false when config exists")
 	}
 }
+
+func TestLoadGlobalConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	if cfg.Defaults.IncludeVendors == nil {
+		t.Error("LoadGlobalConfig() without a file should return the default config")
+	}
+
+	configDir := filepath.Join(tempDir, "airuler")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	content := `vendors:
+  github.com/acme:
+    auth:
+      method: token
+      token_env: ACME_TOKEN
+`
+	if err := os.WriteFile(filepath.Join(configDir, "airuler.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err = LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	auth := cfg.Vendors["github.com/acme"].Auth
+	if auth == nil || auth.Method != "token" || auth.TokenEnv != "ACME_TOKEN" {
+		t.Errorf("LoadGlobalConfig() vendor auth = %+v, want token from ACME_TOKEN", auth)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "airuler.yaml")
+	content := `defaults:
+  include_vendors: [go-rules]
+vendor_overrides:
+  go-rules:
+    template_defaults:
+      language: go
+    variables:
+      team: platform
+    targets:
+      claude:
+        default_mode: memory
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Defaults.IncludeVendors) != 1 || cfg.Defaults.IncludeVendors[0] != "go-rules" {
+		t.Errorf("LoadConfig() include_vendors = %v", cfg.Defaults.IncludeVendors)
+	}
+	override := cfg.VendorOverrides["go-rules"]
+	if override.TemplateDefaults["language"] != "go" || override.Variables["team"] != "platform" {
+		t.Errorf("LoadConfig() vendor override = %+v", override)
+	}
+	if override.Targets["claude"].DefaultMode != "memory" {
+		t.Errorf("LoadConfig() claude default_mode = %q, want memory", override.Targets["claude"].DefaultMode)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadConfig() of a missing file succeeded")
+	}
+}
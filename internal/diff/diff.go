@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+// Package diff compares directory trees and prints the differences as unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	linediff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Change statuses
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+// contextLines is how many unchanged lines surround each hunk, as in git diff
+const contextLines = 3
+
+// FileChange is a file that differs between two trees
+type FileChange struct {
+	Path string // slash-separated path relative to the tree root
+	Old  []byte // nil when the file was added
+	New  []byte // nil when the file was removed
+}
+
+// Status returns Added, Removed or Modified
+func (c FileChange) Status() string {
+	switch {
+	case c.Old == nil:
+		return Added
+	case c.New == nil:
+		return Removed
+	}
+	return Modified
+}
+
+// CompareDirs returns the files that differ between two directories, sorted by path. A missing
+// directory counts as empty. When include is set, only files it accepts are compared.
+func CompareDirs(oldDir, newDir string, include func(path string) bool) ([]FileChange, error) {
+	oldFiles, err := ReadTree(oldDir, include)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := ReadTree(newDir, include)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(oldFiles, newFiles), nil
+}
+
+// Compare returns the files that differ between two sets of files keyed by path, sorted by path
+func Compare(oldFiles, newFiles map[string][]byte) []FileChange {
+	var changes []FileChange
+	for path, oldContent := range oldFiles {
+		newContent, exists := newFiles[path]
+		switch {
+		case !exists:
+			changes = append(changes, FileChange{Path: path, Old: oldContent})
+		case !bytes.Equal(oldContent, newContent):
+			changes = append(changes, FileChange{Path: path, Old: oldContent, New: newContent})
+		}
+	}
+	for path, newContent := range newFiles {
+		if _, exists := oldFiles[path]; !exists {
+			changes = append(changes, FileChange{Path: path, New: newContent})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// ReadTree reads every regular file below dir, keyed by slash-separated relative path. A
+// missing directory has no files.
+func ReadTree(dir string, include func(path string) bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if include != nil && !include(relPath) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if content == nil {
+			content = []byte{} // an empty file still exists
+		}
+		files[relPath] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// WriteUnified writes changes as a git-style unified diff, with paths prefixed by a/ and b/
+func WriteUnified(w io.Writer, changes []FileChange) error {
+	patches := make([]fdiff.FilePatch, len(changes))
+	for i, change := range changes {
+		patches[i] = newFilePatch(change)
+	}
+	return fdiff.NewUnifiedEncoder(w, contextLines).Encode(patch(patches))
+}
+
+// patch adapts file changes to the patch format understood by go-git's unified encoder
+type patch []fdiff.FilePatch
+
+func (p patch) FilePatches() []fdiff.FilePatch { return p }
+func (p patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to fdiff.File
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func newFilePatch(change FileChange) *filePatch {
+	fp := &filePatch{}
+	if change.Old != nil {
+		fp.from = file{path: change.Path, content: change.Old}
+	}
+	if change.New != nil {
+		fp.to = file{path: change.Path, content: change.New}
+	}
+
+	fp.binary = isBinary(change.Old) || isBinary(change.New)
+	if fp.binary {
+		return fp
+	}
+	for _, d := range linediff.Do(string(change.Old), string(change.New)) {
+		operation := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			operation = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			operation = fdiff.Delete
+		}
+		fp.chunks = append(fp.chunks, chunk{content: d.Text, operation: operation})
+	}
+	return fp
+}
+
+func (p *filePatch) IsBinary() bool                  { return p.binary }
+func (p *filePatch) Files() (fdiff.File, fdiff.File) { return p.from, p.to }
+func (p *filePatch) Chunks() []fdiff.Chunk           { return p.chunks }
+
+type file struct {
+	path    string
+	content []byte
+}
+
+func (f file) Hash() plumbing.Hash     { return plumbing.ComputeHash(plumbing.BlobObject, f.content) }
+func (f file) Mode() filemode.FileMode { return filemode.Regular }
+func (f file) Path() string            { return f.path }
+
+type chunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c chunk) Content() string       { return c.content }
+func (c chunk) Type() fdiff.Operation { return c.operation }
+
+// isBinary reports whether content looks binary, like git: a NUL byte in the first 8000 bytes
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
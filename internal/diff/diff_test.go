@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package diff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestCompareDirs(t *testing.T) {
+	oldDir := filepath.Join(t.TempDir(), "old")
+	newDir := filepath.Join(t.TempDir(), "new")
+	writeFiles(t, oldDir, map[string]string{
+		"same.tmpl":             "unchanged\n",
+		"changed.tmpl":          "one\ntwo\n",
+		"removed.tmpl":          "gone\n",
+		"partials/header.ptmpl": "header\n",
+		"README.md":             "skipped\n",
+	})
+	writeFiles(t, newDir, map[string]string{
+		"same.tmpl":             "unchanged\n",
+		"changed.tmpl":          "one\nthree\n",
+		"added.tmpl":            "new\n",
+		"partials/header.ptmpl": "header v2\n",
+		"README.md":             "skipped too\n",
+	})
+
+	changes, err := CompareDirs(oldDir, newDir, func(path string) bool {
+		return strings.HasSuffix(path, "tmpl")
+	})
+	if err != nil {
+		t.Fatalf("CompareDirs() error = %v", err)
+	}
+
+	want := []struct{ path, status string }{
+		{"added.tmpl", Added},
+		{"changed.tmpl", Modified},
+		{"partials/header.ptmpl", Modified},
+		{"removed.tmpl", Removed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("CompareDirs() returned %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, change := range changes {
+		if change.Path != want[i].path || change.Status() != want[i].status {
+			t.Errorf("change %d = %s %s, want %s %s", i, change.Status(), change.Path, want[i].status, want[i].path)
+		}
+	}
+
+	missing, err := CompareDirs(filepath.Join(t.TempDir(), "missing"), newDir, nil)
+	if err != nil || len(missing) != 5 {
+		t.Errorf("CompareDirs() with a missing directory = %d changes, %v; want 5 added", len(missing), err)
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteUnified(&out, []FileChange{
+		{Path: "rule.md", Old: []byte("one\ntwo\nthree\n"), New: []byte("one\n2\nthree\n")},
+		{Path: "new.md", New: []byte("hello\n")},
+	})
+	if err != nil {
+		t.Fatalf("WriteUnified() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"diff --git a/rule.md b/rule.md",
+		"--- a/rule.md\n+++ b/rule.md\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		"new file mode 100644",
+		"--- /dev/null\n+++ b/new.md\n@@ -0,0 +1 @@\n+hello\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("unified diff is missing %q:\n%s", want, out.String())
+		}
+	}
+}
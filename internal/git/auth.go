@@ -0,0 +1,395 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// Authentication methods for git remotes
+const (
+	AuthAuto             = ""
+	AuthNone             = "none"
+	AuthSSHAgent         = "ssh-agent"
+	AuthSSHKey           = "ssh-key"
+	AuthToken            = "token"
+	AuthNetrc            = "netrc"
+	AuthCredentialHelper = "credential-helper"
+)
+
+// defaultSSHKeys are tried, in order, when no SSH agent or key file is configured
+var defaultSSHKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// AuthConfig describes how to authenticate to a git remote.
+// Secrets are never stored here; tokens and passphrases are read from environment variables.
+type AuthConfig struct {
+	Method        string // one of the Auth* constants; empty picks a method from the URL
+	Username      string // HTTPS username used with tokens (default "git") or SSH user (default "git")
+	TokenEnv      string // environment variable holding an HTTPS token
+	SSHKey        string // path to a private key file
+	PassphraseEnv string // environment variable holding the SSH key passphrase
+}
+
+// resolvedAuth is an authentication method ready for go-git, with a description for error messages
+type resolvedAuth struct {
+	method      transport.AuthMethod
+	description string
+}
+
+// isSSHURL reports whether a remote URL uses SSH, including scp-like "user@host:path" URLs
+func isSSHURL(remote string) bool {
+	if strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(remote, "://") {
+		return false
+	}
+	at := strings.Index(remote, "@")
+	colon := strings.Index(remote, ":")
+	return at > 0 && colon > at
+}
+
+// isHTTPURL reports whether a remote URL uses HTTP or HTTPS
+func isHTTPURL(remote string) bool {
+	return strings.HasPrefix(remote, "https://") || strings.HasPrefix(remote, "http://")
+}
+
+// remoteHost returns the host and path of a remote URL
+func remoteHost(remote string) (string, string) {
+	if isSSHURL(remote) && !strings.Contains(remote, "://") {
+		hostPart, path, _ := strings.Cut(remote, ":")
+		if _, host, found := strings.Cut(hostPart, "@"); found {
+			hostPart = host
+		}
+		return hostPart, path
+	}
+
+	parsed, err := url.Parse(remote)
+	if err != nil {
+		return "", ""
+	}
+	return parsed.Hostname(), strings.TrimPrefix(parsed.Path, "/")
+}
+
+// resolveAuth determines the credentials to use for a remote
+func resolveAuth(remote string, cfg AuthConfig) (resolvedAuth, error) {
+	method := cfg.Method
+	if method == AuthAuto {
+		method = autoAuthMethod(remote, cfg)
+	}
+
+	switch method {
+	case AuthNone:
+		return resolvedAuth{description: "anonymous access"}, nil
+	case AuthSSHAgent:
+		return sshAgentAuth(cfg)
+	case AuthSSHKey:
+		return sshKeyAuth(cfg, cfg.SSHKey)
+	case AuthToken:
+		return tokenAuth(cfg)
+	case AuthNetrc:
+		return netrcAuth(remote)
+	case AuthCredentialHelper:
+		return credentialHelperAuth(remote)
+	}
+
+	return resolvedAuth{}, fmt.Errorf("unknown auth method %q (use %s, %s, %s, %s, %s or %s)", cfg.Method,
+		AuthSSHAgent, AuthSSHKey, AuthToken, AuthNetrc, AuthCredentialHelper, AuthNone)
+}
+
+// autoAuthMethod picks an authentication method from the URL and the environment
+func autoAuthMethod(remote string, cfg AuthConfig) string {
+	switch {
+	case isSSHURL(remote):
+		if cfg.SSHKey != "" {
+			return AuthSSHKey
+		}
+		if os.Getenv("SSH_AUTH_SOCK") != "" {
+			return AuthSSHAgent
+		}
+		return AuthSSHKey
+	case isHTTPURL(remote):
+		if cfg.TokenEnv != "" {
+			return AuthToken
+		}
+		host, _ := remoteHost(remote)
+		if _, _, ok := lookupNetrc(host); ok {
+			return AuthNetrc
+		}
+	}
+	return AuthNone
+}
+
+func sshUser(cfg AuthConfig) string {
+	if cfg.Username != "" {
+		return cfg.Username
+	}
+	return "git"
+}
+
+func sshAgentAuth(cfg AuthConfig) (resolvedAuth, error) {
+	description := "SSH agent"
+	auth, err := gitssh.NewSSHAgentAuth(sshUser(cfg))
+	if err != nil {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: %w", description, err)
+	}
+	return resolvedAuth{method: auth, description: description}, nil
+}
+
+func sshKeyAuth(cfg AuthConfig, keyPath string) (resolvedAuth, error) {
+	if keyPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return resolvedAuth{description: "SSH key"}, fmt.Errorf("SSH key: %w", err)
+		}
+		for _, name := range defaultSSHKeys {
+			candidate := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(candidate); err == nil {
+				keyPath = candidate
+				break
+			}
+		}
+		if keyPath == "" {
+			return resolvedAuth{description: "SSH key"},
+				fmt.Errorf("SSH key: no SSH agent running and no key found in ~/.ssh (%s)", strings.Join(defaultSSHKeys, ", "))
+		}
+	}
+
+	keyPath = expandHome(keyPath)
+	description := fmt.Sprintf("SSH key %s", keyPath)
+
+	passphrase := ""
+	if cfg.PassphraseEnv != "" {
+		passphrase = os.Getenv(cfg.PassphraseEnv)
+	}
+
+	auth, err := gitssh.NewPublicKeysFromFile(sshUser(cfg), keyPath, passphrase)
+	if err != nil {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: %w", description, err)
+	}
+	return resolvedAuth{method: auth, description: description}, nil
+}
+
+func tokenAuth(cfg AuthConfig) (resolvedAuth, error) {
+	if cfg.TokenEnv == "" {
+		return resolvedAuth{description: "token"}, fmt.Errorf("token: no token_env configured")
+	}
+
+	description := fmt.Sprintf("token from $%s", cfg.TokenEnv)
+	token := os.Getenv(cfg.TokenEnv)
+	if token == "" {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: environment variable is not set", description)
+	}
+
+	username := cfg.Username
+	if username == "" {
+		username = "git"
+	}
+	return resolvedAuth{
+		method:      &githttp.BasicAuth{Username: username, Password: token},
+		description: description,
+	}, nil
+}
+
+func netrcAuth(remote string) (resolvedAuth, error) {
+	host, _ := remoteHost(remote)
+	description := fmt.Sprintf("netrc entry for %s", host)
+
+	login, password, ok := lookupNetrc(host)
+	if !ok {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: no matching machine in %s", description, netrcPath())
+	}
+	return resolvedAuth{
+		method:      &githttp.BasicAuth{Username: login, Password: password},
+		description: description,
+	}, nil
+}
+
+// credentialHelperAuth asks the git credential helpers configured for the user, without prompting
+func credentialHelperAuth(remote string) (resolvedAuth, error) {
+	description := "git credential helper"
+
+	parsed, err := url.Parse(remote)
+	if err != nil || !isHTTPURL(remote) {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: only HTTP(S) URLs are supported", description)
+	}
+
+	input := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", parsed.Scheme, parsed.Host, strings.TrimPrefix(parsed.Path, "/"))
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return resolvedAuth{description: description}, fmt.Errorf("%s: %s", description, detail)
+	}
+
+	var username, password string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+	if password == "" {
+		return resolvedAuth{description: description}, fmt.Errorf("%s: no credentials for %s", description, parsed.Host)
+	}
+
+	return resolvedAuth{
+		method:      &githttp.BasicAuth{Username: username, Password: password},
+		description: description,
+	}, nil
+}
+
+// netrcPath returns the netrc file consulted for credentials
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// lookupNetrc returns the login and password for host from the netrc file
+func lookupNetrc(host string) (string, string, bool) {
+	path := netrcPath()
+	if path == "" || host == "" {
+		return "", "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	return parseNetrc(string(data), host)
+}
+
+// parseNetrc finds the credentials for host in netrc content, falling back to a default entry
+func parseNetrc(content, host string) (string, string, bool) {
+	fields := strings.Fields(content)
+
+	var machine, login, password string
+	var defaultLogin, defaultPassword string
+	inDefault, found, foundDefault := false, false, false
+
+	flush := func() {
+		switch {
+		case inDefault && !foundDefault:
+			defaultLogin, defaultPassword, foundDefault = login, password, true
+		case machine == host && !found:
+			found = true
+		}
+	}
+
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine", "default":
+			if machine != "" || inDefault {
+				flush()
+				if found {
+					return login, password, true
+				}
+			}
+			machine, login, password, inDefault = "", "", "", fields[i] == "default"
+			if !inDefault && i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "login":
+			if i+1 < len(fields) {
+				i++
+				login = fields[i]
+			}
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				password = fields[i]
+			}
+		case "macdef":
+			// Macro definitions run to the end of the file in practice; stop parsing
+			i = len(fields)
+		}
+	}
+	if machine != "" || inDefault {
+		flush()
+		if found {
+			return login, password, true
+		}
+	}
+
+	if foundDefault {
+		return defaultLogin, defaultPassword, true
+	}
+	return "", "", false
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// withAuth runs a network operation with the repository's credentials. In automatic mode an
+// HTTPS remote that rejects the first attempt is retried once with the git credential helper.
+// Errors name the authentication method that was tried.
+func (r *GoGitRepository) withAuth(action string, operation func(transport.AuthMethod) error) error {
+	auth, err := resolveAuth(r.URL, r.Options.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	err = operation(auth.method)
+	if err != nil && isAuthError(err) && r.Options.Auth.Method == AuthAuto && isHTTPURL(r.URL) {
+		helper, helperErr := credentialHelperAuth(r.URL)
+		if helperErr != nil {
+			return fmt.Errorf("failed to %s using %s: %w (%v)", action, auth.description, err, helperErr)
+		}
+		auth.description += ", then " + helper.description
+		err = operation(helper.method)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s using %s: %w", action, auth.description, err)
+	}
+
+	return nil
+}
+
+// isAuthError reports whether a transport error was caused by missing or rejected credentials
+func isAuthError(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed)
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestRemoteURLKinds(t *testing.T) {
+	tests := []struct {
+		url      string
+		ssh      bool
+		http     bool
+		wantHost string
+	}{
+		{url: "git@github.com:acme/rules.git", ssh: true, wantHost: "github.com"},
+		{url: "ssh://git@gitlab.example.com/acme/rules.git", ssh: true, wantHost: "gitlab.example.com"},
+		{url: "https://github.com/acme/rules", http: true, wantHost: "github.com"},
+		{url: "http://git.internal:8080/rules.git", http: true, wantHost: "git.internal"},
+		{url: "file:///tmp/rules", wantHost: ""},
+		{url: "/tmp/rules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := isSSHURL(tt.url); got != tt.ssh {
+				t.Errorf("isSSHURL() = %v, want %v", got, tt.ssh)
+			}
+			if got := isHTTPURL(tt.url); got != tt.http {
+				t.Errorf("isHTTPURL() = %v, want %v", got, tt.http)
+			}
+			if host, _ := remoteHost(tt.url); host != tt.wantHost {
+				t.Errorf("remoteHost() = %q, want %q", host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestParseNetrc(t *testing.T) {
+	content := `machine github.com login alice password secret1
+machine gitlab.com
+  login bob
+  password secret2
+default login anon password guest
+`
+
+	tests := []struct {
+		host      string
+		wantLogin string
+		wantPass  string
+		wantOK    bool
+	}{
+		{host: "github.com", wantLogin: "alice", wantPass: "secret1", wantOK: true},
+		{host: "gitlab.com", wantLogin: "bob", wantPass: "secret2", wantOK: true},
+		{host: "example.com", wantLogin: "anon", wantPass: "guest", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			login, password, ok := parseNetrc(content, tt.host)
+			if ok != tt.wantOK || login != tt.wantLogin || password != tt.wantPass {
+				t.Errorf("parseNetrc() = %q, %q, %v; want %q, %q, %v", login, password, ok, tt.wantLogin, tt.wantPass, tt.wantOK)
+			}
+		})
+	}
+
+	if _, _, ok := parseNetrc("machine github.com login a password b", "gitlab.com"); ok {
+		t.Error("parseNetrc() matched a host without an entry or default")
+	}
+}
+
+func TestResolveAuth(t *testing.T) {
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(netrc, []byte("machine git.example.com login carol password hunter2\n"), 0600); err != nil {
+		t.Fatalf("Failed to write netrc: %v", err)
+	}
+	t.Setenv("NETRC", netrc)
+	t.Setenv("AIRULER_TEST_TOKEN", "tok")
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	t.Run("token from environment", func(t *testing.T) {
+		auth, err := resolveAuth("https://github.com/acme/rules", AuthConfig{TokenEnv: "AIRULER_TEST_TOKEN"})
+		if err != nil {
+			t.Fatalf("resolveAuth() error = %v", err)
+		}
+		basic, ok := auth.method.(*githttp.BasicAuth)
+		if !ok || basic.Username != "git" || basic.Password != "tok" {
+			t.Errorf("resolveAuth() method = %#v, want basic auth git:tok", auth.method)
+		}
+		if auth.description != "token from $AIRULER_TEST_TOKEN" {
+			t.Errorf("description = %q", auth.description)
+		}
+	})
+
+	t.Run("missing token names the method", func(t *testing.T) {
+		_, err := resolveAuth("https://github.com/acme/rules", AuthConfig{Method: AuthToken, TokenEnv: "AIRULER_TEST_MISSING"})
+		if err == nil || !strings.Contains(err.Error(), "token from $AIRULER_TEST_MISSING") {
+			t.Errorf("resolveAuth() error = %v, want it to name the token variable", err)
+		}
+	})
+
+	t.Run("netrc entry for host", func(t *testing.T) {
+		auth, err := resolveAuth("https://git.example.com/acme/rules.git", AuthConfig{})
+		if err != nil {
+			t.Fatalf("resolveAuth() error = %v", err)
+		}
+		basic, ok := auth.method.(*githttp.BasicAuth)
+		if !ok || basic.Username != "carol" || basic.Password != "hunter2" {
+			t.Errorf("resolveAuth() method = %#v, want netrc credentials", auth.method)
+		}
+	})
+
+	t.Run("anonymous without credentials", func(t *testing.T) {
+		auth, err := resolveAuth("https://github.com/acme/rules", AuthConfig{})
+		if err != nil || auth.method != nil || auth.description != "anonymous access" {
+			t.Errorf("resolveAuth() = %#v, %v; want anonymous access", auth, err)
+		}
+	})
+
+	t.Run("local repositories need no auth", func(t *testing.T) {
+		auth, err := resolveAuth("file:///tmp/rules", AuthConfig{})
+		if err != nil || auth.method != nil {
+			t.Errorf("resolveAuth() = %#v, %v; want no auth", auth, err)
+		}
+	})
+
+	t.Run("missing SSH key names the file", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "id_missing")
+		_, err := resolveAuth("git@github.com:acme/rules.git", AuthConfig{SSHKey: keyPath})
+		if err == nil || !strings.Contains(err.Error(), "SSH key "+keyPath) {
+			t.Errorf("resolveAuth() error = %v, want it to name %s", err, keyPath)
+		}
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		if _, err := resolveAuth("https://github.com/acme/rules", AuthConfig{Method: "kerberos"}); err == nil {
+			t.Error("resolveAuth() expected error for unknown method")
+		}
+	})
+
+	t.Run("credential helper", func(t *testing.T) {
+		t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+		t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+		t.Setenv("GIT_CONFIG_COUNT", "1")
+		t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+		t.Setenv("GIT_CONFIG_VALUE_0", "!f() { echo username=dave; echo password=s3cret; }; f")
+
+		auth, err := resolveAuth("https://github.com/acme/rules", AuthConfig{Method: AuthCredentialHelper})
+		if err != nil {
+			t.Fatalf("resolveAuth() error = %v", err)
+		}
+		basic, ok := auth.method.(*githttp.BasicAuth)
+		if !ok || basic.Username != "dave" || basic.Password != "s3cret" {
+			t.Errorf("resolveAuth() method = %#v, want credentials from helper", auth.method)
+		}
+	})
+}
+
+func TestWithAuthErrorNamesMethod(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_COUNT", "0")
+
+	repo := &GoGitRepository{
+		URL:     "https://git.example.com/acme/rules.git",
+		Options: RepositoryOptions{Auth: AuthConfig{Method: AuthNone}},
+	}
+	err := repo.withAuth("clone repository", func(auth transport.AuthMethod) error {
+		return transport.ErrAuthenticationRequired
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to clone repository using anonymous access") {
+		t.Errorf("withAuth() error = %v, want it to name the auth method", err)
+	}
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+	t.Setenv("GIT_CONFIG_VALUE_0", "!f() { echo username=dave; echo password=s3cret; }; f")
+
+	repo.Options.Auth = AuthConfig{}
+	attempts := 0
+	err = repo.withAuth("clone repository", func(auth transport.AuthMethod) error {
+		attempts++
+		if auth == nil {
+			return transport.ErrAuthenticationRequired
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("withAuth() = %v after %d attempts, want a successful retry with the credential helper", err, attempts)
+	}
+}
@@ -0,0 +1,720 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// CLIRepository implements Repository by running the git command line tool. Unlike go-git it
+// honours the user's git configuration: credential helpers, url.<base>.insteadOf rewrites,
+// partial clones and Git LFS. It needs git 2.31 or later.
+type CLIRepository struct {
+	URL       string
+	LocalPath string
+	Options   RepositoryOptions
+}
+
+// CLIRepositoryFactory creates repositories backed by the git command line tool
+type CLIRepositoryFactory struct{}
+
+// NewCLIRepositoryFactory creates a new factory for git command line operations
+func NewCLIRepositoryFactory() *CLIRepositoryFactory {
+	return &CLIRepositoryFactory{}
+}
+
+// NewRepository creates a new repository instance using the git command line tool
+func (f *CLIRepositoryFactory) NewRepository(url, localPath string) Repository {
+	return &CLIRepository{
+		URL:       url,
+		LocalPath: localPath,
+	}
+}
+
+// NewRepositoryWithOptions creates a new repository instance using the git command line tool
+// with clone and checkout options
+func (f *CLIRepositoryFactory) NewRepositoryWithOptions(url, localPath string, opts RepositoryOptions) Repository {
+	return &CLIRepository{
+		URL:       url,
+		LocalPath: localPath,
+		Options:   opts,
+	}
+}
+
+// Clone clones the repository to the local path. Sparse clones are partial clones that only
+// download the blobs below the sparse paths.
+func (r *CLIRepository) Clone() error {
+	// Ensure parent directory exists
+	parentDir := filepath.Dir(r.LocalPath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	args := []string{"clone", "--quiet"}
+	if r.Options.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(r.Options.Depth))
+	}
+	if len(r.Options.SparsePaths) > 0 {
+		args = append(args, "--no-checkout", "--filter=blob:none")
+	}
+	args = append(args, "--", r.URL, r.LocalPath)
+
+	if _, err := r.runRemote("clone repository", "", args...); err != nil {
+		_ = os.RemoveAll(r.LocalPath)
+		return err
+	}
+
+	if len(r.Options.SparsePaths) == 0 {
+		return nil
+	}
+
+	// Sparse paths are directories relative to the repository root, like go-git's
+	patterns := make([]string, len(r.Options.SparsePaths))
+	for i, path := range r.Options.SparsePaths {
+		patterns[i] = "/" + strings.Trim(filepath.ToSlash(path), "/") + "/"
+	}
+	if _, err := r.run(r.LocalPath, append([]string{"sparse-checkout", "set", "--no-cone", "--"}, patterns...)...); err != nil {
+		return fmt.Errorf("failed to set sparse paths %s: %w", strings.Join(r.Options.SparsePaths, ", "), err)
+	}
+	if _, err := r.runRemote("check out "+strings.Join(r.Options.SparsePaths, ", "), r.LocalPath, "checkout", "--quiet"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Pull updates the repository from remote, fast-forwarding the current branch
+func (r *CLIRepository) Pull() error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	// Shallow and sparse clones fetch the new tip and reset to it, keeping depth and sparse paths
+	if r.Options.Depth > 0 || len(r.Options.SparsePaths) > 0 {
+		remote, err := r.GetRemoteCommit()
+		if err != nil {
+			return err
+		}
+		return r.ResetToCommit(remote)
+	}
+
+	_, err := r.runRemote("pull repository", r.LocalPath, "pull", "--quiet", "--ff-only")
+	return err
+}
+
+// GetCurrentCommit returns the current commit hash
+func (r *CLIRepository) GetCurrentCommit() (string, error) {
+	if !r.Exists() {
+		return "", fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	commit, err := r.run(r.LocalPath, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return commit, nil
+}
+
+// GetRemoteCommit fetches from the remote and returns the commit of its default branch
+func (r *CLIRepository) GetRemoteCommit() (string, error) {
+	if !r.Exists() {
+		return "", fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	args := []string{"fetch", "--quiet"}
+	if r.Options.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(r.Options.Depth))
+	}
+	if _, err := r.runRemote("fetch from remote", r.LocalPath, append(args, "origin")...); err != nil {
+		return "", err
+	}
+
+	// Try to get remote HEAD, with fallbacks to main/master
+	for _, branch := range []string{"HEAD", "main", "master"} {
+		ref := "refs/remotes/origin/" + branch + "^{commit}"
+		if commit, err := r.run(r.LocalPath, "rev-parse", "--verify", "--quiet", ref); err == nil {
+			return commit, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to get remote commit: no valid remote reference found")
+}
+
+// HasUpdates checks if there are updates available from remote
+func (r *CLIRepository) HasUpdates() (bool, error) {
+	current, err := r.GetCurrentCommit()
+	if err != nil {
+		return false, err
+	}
+
+	remote, err := r.GetRemoteCommit()
+	if err != nil {
+		return false, err
+	}
+
+	return current != remote, nil
+}
+
+// Exists checks if the repository exists locally
+func (r *CLIRepository) Exists() bool {
+	gitDir := filepath.Join(r.LocalPath, ".git")
+	_, err := os.Stat(gitDir)
+	return err == nil
+}
+
+// Remove removes the local repository directory
+func (r *CLIRepository) Remove() error {
+	return os.RemoveAll(r.LocalPath)
+}
+
+// CheckoutCommit checks out a specific commit, leaving HEAD detached
+func (r *CLIRepository) CheckoutCommit(commit string) error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	if err := r.ensureCommit(commit); err != nil {
+		return err
+	}
+
+	if _, err := r.runRemote("checkout commit "+commit, r.LocalPath, "checkout", "--quiet", "--detach", commit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CheckoutMainBranch checks out the main/master branch
+func (r *CLIRepository) CheckoutMainBranch() error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	// Try to checkout main branch, fallback to master if main doesn't exist
+	var err error
+	for _, branch := range []string{"main", "master"} {
+		if _, err = r.runRemote("checkout "+branch, r.LocalPath, "checkout", "--quiet", branch); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to checkout main/master branch: %w", err)
+}
+
+// ResetToCommit resets the repository to a specific commit (hard reset)
+func (r *CLIRepository) ResetToCommit(commit string) error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	if err := r.ensureCommit(commit); err != nil {
+		return err
+	}
+
+	if _, err := r.runRemote("reset to commit "+commit, r.LocalPath, "reset", "--quiet", "--hard", commit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListRemoteRefs lists the default branch, branches and tags advertised by the remote
+func (r *CLIRepository) ListRemoteRefs() ([]RemoteRef, error) {
+	output, err := r.runRemote("list remote references", "", "ls-remote", "--symref", "--", r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string)
+	peeled := make(map[string]string)
+	headTarget, headCommit := "", ""
+	for _, line := range strings.Split(output, "\n") {
+		value, name, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(value, "ref: ") && name == "HEAD":
+			headTarget = strings.TrimPrefix(value, "ref: ")
+		case name == "HEAD":
+			headCommit = value
+		case strings.HasSuffix(name, "^{}"):
+			peeled[strings.TrimSuffix(name, "^{}")] = value
+		default:
+			hashes[name] = value
+		}
+	}
+
+	var result []RemoteRef
+	if headTarget != "" && hashes[headTarget] != "" {
+		headCommit = hashes[headTarget]
+	}
+	if headCommit != "" {
+		result = append(result, RemoteRef{Name: "HEAD", Commit: headCommit, Kind: RefHead})
+	}
+
+	for name, commit := range hashes {
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			result = append(result, RemoteRef{Name: strings.TrimPrefix(name, "refs/heads/"), Commit: commit, Kind: RefBranch})
+		case strings.HasPrefix(name, "refs/tags/"):
+			// Annotated tags point at a tag object; use the commit it peels to
+			if target, ok := peeled[name]; ok {
+				commit = target
+			}
+			result = append(result, RemoteRef{Name: strings.TrimPrefix(name, "refs/tags/"), Commit: commit, Kind: RefTag})
+		}
+	}
+
+	return result, nil
+}
+
+// FetchRefs fetches all branches and tags from the remote into the local repository
+func (r *CLIRepository) FetchRefs() error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	return r.fetchAll(r.Options.Depth)
+}
+
+// VerifySignature checks that a commit, or the annotated tag pointing at it, carries a valid
+// signature from one of the trusted keys, reading the raw objects from git
+func (r *CLIRepository) VerifySignature(commit, tag string, keys TrustedKeys) (string, error) {
+	if !r.Exists() {
+		return "", fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	if tag != "" {
+		signer, signed, err := r.verifyTag(commit, tag, keys)
+		if signed || err != nil {
+			return signer, err
+		}
+	}
+
+	if err := r.ensureCommit(commit); err != nil {
+		return "", err
+	}
+	raw, err := r.output(r.LocalPath, nil, "cat-file", "commit", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	payload, signature := splitCommitSignature(raw)
+	if signature == "" {
+		if tag != "" {
+			return "", fmt.Errorf("neither tag %s nor commit %s is signed", tag, shortHash(commit))
+		}
+		return "", fmt.Errorf("commit %s is not signed", shortHash(commit))
+	}
+
+	return verifyPayload("commit "+shortHash(commit), signature, memoryObject(payload), keys)
+}
+
+// verifyTag verifies a signed annotated tag; signed is false for lightweight and unsigned tags
+func (r *CLIRepository) verifyTag(commit, tag string, keys TrustedKeys) (string, bool, error) {
+	ref := "refs/tags/" + tag
+	object, err := r.run(r.LocalPath, "rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		if err := r.fetchAll(r.Options.Depth); err != nil {
+			return "", false, err
+		}
+		if object, err = r.run(r.LocalPath, "rev-parse", "--verify", "--quiet", ref); err != nil {
+			return "", false, fmt.Errorf("failed to find tag %s: %w", tag, err)
+		}
+	}
+
+	if objectType, err := r.run(r.LocalPath, "cat-file", "-t", object); err != nil || objectType != "tag" {
+		return "", false, nil //nolint:nilerr // Lightweight tags point straight at the commit
+	}
+	raw, err := r.output(r.LocalPath, nil, "cat-file", "tag", object)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read tag %s: %w", tag, err)
+	}
+	payload, signature := splitTagSignature(raw)
+	if signature == "" {
+		return "", false, nil
+	}
+
+	target, err := r.run(r.LocalPath, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", true, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	if target != commit {
+		return "", true, fmt.Errorf("tag %s points at %s, not %s", tag, shortHash(target), shortHash(commit))
+	}
+
+	signer, err := verifyPayload("tag "+tag, signature, memoryObject(payload), keys)
+	return signer, true, err
+}
+
+// ExportTree writes the files below path at a commit into dest, fetching the commit if needed
+func (r *CLIRepository) ExportTree(commit, path, dest string) error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	if err := r.ensureCommit(commit); err != nil {
+		return err
+	}
+
+	treeish := commit
+	if path != "" {
+		treeish = commit + ":" + strings.Trim(path, "/")
+		if objectType, err := r.run(r.LocalPath, "cat-file", "-t", treeish); err != nil || objectType != "tree" {
+			return fmt.Errorf("path %s not found at commit %s", path, shortHash(commit))
+		}
+	}
+	archive, err := r.output(r.LocalPath, nil, "archive", "--format=tar", treeish)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", shortHash(commit), err)
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive of %s: %w", shortHash(commit), err)
+		}
+		// Symlinks and submodules have no content of their own
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", header.Name, err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", header.Name, err)
+		}
+	}
+}
+
+// Status lists the files with uncommitted changes, preferring the working tree state over the index
+func (r *CLIRepository) Status() ([]FileChange, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	output, err := r.output(r.LocalPath, nil, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+
+	var changes []FileChange
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		staging, worktree, path := entry[0], entry[1], entry[3:]
+		// Renames and copies are followed by the original path
+		if staging == 'R' || staging == 'C' {
+			i++
+		}
+
+		code := worktree
+		if code == ' ' {
+			code = staging
+		}
+		changes = append(changes, FileChange{Path: path, Status: fileStatusFromLetter(code)})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// IsDirty reports whether the working tree or index has uncommitted changes
+func (r *CLIRepository) IsDirty() (bool, error) {
+	status, err := r.Status()
+	if err != nil {
+		return false, err
+	}
+	return len(status) > 0, nil
+}
+
+// DiffFiles lists the files that differ between the trees of two commits
+func (r *CLIRepository) DiffFiles(oldCommit, newCommit string) ([]FileChange, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	output, err := r.output(r.LocalPath, nil, "diff", "--name-status", "--no-renames", "-z", oldCommit, newCommit, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s and %s: %w", shortHash(oldCommit), shortHash(newCommit), err)
+	}
+
+	var changes []FileChange
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := FileModified
+		switch fields[i] {
+		case "A":
+			status = FileAdded
+		case "D":
+			status = FileDeleted
+		}
+		changes = append(changes, FileChange{Path: fields[i+1], Status: status})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// fetchAll fetches every branch and tag, limited to depth commits from each tip (0 for full history)
+func (r *CLIRepository) fetchAll(depth int) error {
+	args := []string{"fetch", "--quiet", "--force", "--tags"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	args = append(args, "origin", "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*")
+	_, err := r.runRemote("fetch from remote", r.LocalPath, args...)
+	return err
+}
+
+// ensureCommit makes sure a commit is available locally. Shallow clones first fetch the tips
+// of all branches and tags, and only fetch the full history when the commit is still missing.
+func (r *CLIRepository) ensureCommit(commit string) error {
+	hasCommit := func() bool {
+		_, err := r.run(r.LocalPath, "cat-file", "-e", commit+"^{commit}")
+		return err == nil
+	}
+	if hasCommit() {
+		return nil
+	}
+
+	shallow, err := r.run(r.LocalPath, "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return fmt.Errorf("failed to read shallow state: %w", err)
+	}
+
+	if shallow == "true" {
+		if err := r.fetchAll(r.Options.Depth); err != nil {
+			return err
+		}
+		if hasCommit() {
+			return nil
+		}
+	}
+
+	depth := 0
+	if shallow == "true" {
+		depth = unshallowDepth
+	}
+	if err := r.fetchAll(depth); err != nil {
+		return err
+	}
+	if !hasCommit() {
+		return fmt.Errorf("commit %s not found in %s", commit, r.URL)
+	}
+
+	return nil
+}
+
+// run runs git in dir and returns its output without the trailing newline
+func (r *CLIRepository) run(dir string, args ...string) (string, error) {
+	output, err := r.output(dir, nil, args...)
+	return strings.TrimRight(string(output), "\n"), err
+}
+
+// runRemote runs a git command that talks to the remote, with the configured credentials
+func (r *CLIRepository) runRemote(action, dir string, args ...string) (string, error) {
+	env, description, err := cliAuthEnv(r.URL, r.Options.Auth)
+	if err != nil {
+		return "", fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	output, err := r.output(dir, env, args...)
+	if err != nil {
+		if description != "" {
+			return "", fmt.Errorf("failed to %s using %s: %w", action, description, err)
+		}
+		return "", fmt.Errorf("failed to %s: %w", action, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// output runs git in dir with extra environment variables and returns its standard output.
+// Git never prompts; errors carry git's own message.
+func (r *CLIRepository) output(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	cmd.Env = append(cmd.Env, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" || !errors.As(err, &exitErr) {
+			detail = err.Error()
+		}
+		return output, fmt.Errorf("git %s: %s", args[0], detail)
+	}
+	return output, nil
+}
+
+// cliAuthEnv returns the environment that gives git the configured credentials, and a
+// description for error messages. Without explicit settings git uses its own configuration,
+// including credential helpers, SSH config and netrc.
+func cliAuthEnv(remote string, cfg AuthConfig) ([]string, string, error) {
+	method := cfg.Method
+	if method == AuthAuto {
+		switch {
+		case isSSHURL(remote) && cfg.SSHKey != "":
+			method = AuthSSHKey
+		case isHTTPURL(remote) && cfg.TokenEnv != "":
+			method = AuthToken
+		default:
+			return nil, "", nil
+		}
+	}
+
+	switch method {
+	case AuthNone:
+		return configEnv("credential.helper", ""), "anonymous access", nil
+	case AuthSSHAgent:
+		if os.Getenv("SSH_AUTH_SOCK") == "" {
+			return nil, "SSH agent", fmt.Errorf("SSH agent: SSH_AUTH_SOCK is not set")
+		}
+		return nil, "SSH agent", nil
+	case AuthSSHKey:
+		if cfg.SSHKey == "" {
+			return nil, "SSH key", nil
+		}
+		keyPath := expandHome(cfg.SSHKey)
+		description := fmt.Sprintf("SSH key %s", keyPath)
+		if cfg.PassphraseEnv != "" {
+			return nil, description, fmt.Errorf("%s: passphrases are not supported by the git backend; add the key to ssh-agent", description)
+		}
+		command := "ssh -o IdentitiesOnly=yes -i " + shellQuote(keyPath)
+		if cfg.Username != "" {
+			command += " -l " + shellQuote(cfg.Username)
+		}
+		return []string{"GIT_SSH_COMMAND=" + command}, description, nil
+	case AuthToken:
+		auth, err := tokenAuth(cfg)
+		if err != nil {
+			return nil, auth.description, err
+		}
+		return basicAuthEnv(auth), auth.description, nil
+	case AuthNetrc:
+		auth, err := netrcAuth(remote)
+		if err != nil {
+			return nil, auth.description, err
+		}
+		return basicAuthEnv(auth), auth.description, nil
+	case AuthCredentialHelper:
+		return nil, "git credential helper", nil
+	}
+
+	return nil, "", fmt.Errorf("unknown auth method %q (use %s, %s, %s, %s, %s or %s)", cfg.Method,
+		AuthSSHAgent, AuthSSHKey, AuthToken, AuthNetrc, AuthCredentialHelper, AuthNone)
+}
+
+// basicAuthEnv passes HTTP basic credentials to git as an extra header, keeping them out of
+// the command line
+func basicAuthEnv(auth resolvedAuth) []string {
+	basic, ok := auth.method.(*githttp.BasicAuth)
+	if !ok {
+		return nil
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(basic.Username + ":" + basic.Password))
+	return configEnv("http.extraHeader", "Authorization: Basic "+credentials)
+}
+
+// configEnv sets a git configuration value through the environment
+func configEnv(key, value string) []string {
+	return []string{"GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=" + key, "GIT_CONFIG_VALUE_0=" + value}
+}
+
+// shellQuote quotes a value for GIT_SSH_COMMAND, which git runs through the shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// splitCommitSignature separates a raw commit into the signed payload and its gpgsig header
+func splitCommitSignature(raw []byte) ([]byte, string) {
+	headers, message, _ := bytes.Cut(raw, []byte("\n\n"))
+
+	var payload bytes.Buffer
+	var signature strings.Builder
+	inSignature := false
+	for _, line := range strings.Split(string(headers), "\n") {
+		switch {
+		case strings.HasPrefix(line, "gpgsig "):
+			inSignature = true
+			signature.WriteString(strings.TrimPrefix(line, "gpgsig ") + "\n")
+		case inSignature && strings.HasPrefix(line, " "):
+			signature.WriteString(strings.TrimPrefix(line, " ") + "\n")
+		default:
+			inSignature = false
+			payload.WriteString(line + "\n")
+		}
+	}
+	payload.WriteString("\n")
+	payload.Write(message)
+	return payload.Bytes(), signature.String()
+}
+
+// splitTagSignature separates a raw tag into the signed payload and the signature appended to
+// its message
+func splitTagSignature(raw []byte) ([]byte, string) {
+	for _, header := range []string{pgpSignatureHeader, sshSignatureHeader} {
+		if index := bytes.Index(raw, []byte(header)); index >= 0 {
+			return raw[:index], string(raw[index:])
+		}
+	}
+	return raw, ""
+}
+
+// memoryObject wraps a payload for verifyPayload
+func memoryObject(payload []byte) *plumbing.MemoryObject {
+	object := &plumbing.MemoryObject{}
+	_, _ = object.Write(payload)
+	return object
+}
+
+// fileStatusFromLetter converts a git status --porcelain letter to a FileStatus
+func fileStatusFromLetter(letter byte) FileStatus {
+	switch letter {
+	case 'M', 'T':
+		return FileModified
+	case 'A':
+		return FileAdded
+	case 'D':
+		return FileDeleted
+	case 'R':
+		return FileRenamed
+	case 'C':
+		return FileCopied
+	case 'U':
+		return FileUnmerged
+	default:
+		return FileUntracked
+	}
+}
+
+// Ensure CLIRepository implements Repository interface
+var _ Repository = (*CLIRepository)(nil)
+var _ RepositoryFactory = (*CLIRepositoryFactory)(nil)
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// forEachBackend runs a test against the go-git and git command line backends
+func forEachBackend(t *testing.T, test func(t *testing.T, factory RepositoryFactory)) {
+	t.Helper()
+
+	for _, backend := range []string{BackendGoGit, BackendCLI} {
+		t.Run(backend, func(t *testing.T) {
+			factory, err := NewRepositoryFactory(backend)
+			if err != nil {
+				t.Fatalf("NewRepositoryFactory(%q) error = %v", backend, err)
+			}
+			test(t, factory)
+		})
+	}
+}
+
+func TestNewRepositoryFactory(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    interface{}
+		wantErr bool
+	}{
+		{backend: "", want: &GoGitRepositoryFactory{}},
+		{backend: BackendGoGit, want: &GoGitRepositoryFactory{}},
+		{backend: BackendCLI, want: &CLIRepositoryFactory{}},
+		{backend: "libgit2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			factory, err := NewRepositoryFactory(tt.backend)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "unknown git backend") {
+					t.Errorf("NewRepositoryFactory(%q) error = %v", tt.backend, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRepositoryFactory(%q) error = %v", tt.backend, err)
+			}
+			if reflect.TypeOf(factory) != reflect.TypeOf(tt.want) {
+				t.Errorf("NewRepositoryFactory(%q) = %T, want %T", tt.backend, factory, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultGitRepositoryFactory_Backend(t *testing.T) {
+	t.Setenv("AIRULER_USE_MOCK_GIT", "")
+	t.Cleanup(func() { defaultBackend = BackendGoGit })
+
+	t.Setenv(BackendEnv, "")
+	if err := SetDefaultBackend(BackendCLI); err != nil {
+		t.Fatalf("SetDefaultBackend() error = %v", err)
+	}
+	if _, ok := DefaultGitRepositoryFactory().(*CLIRepositoryFactory); !ok {
+		t.Error("configured git backend was not used")
+	}
+
+	t.Setenv(BackendEnv, BackendGoGit)
+	if _, ok := DefaultGitRepositoryFactory().(*GoGitRepositoryFactory); !ok {
+		t.Errorf("%s should override the configured backend", BackendEnv)
+	}
+
+	t.Setenv(BackendEnv, "bogus")
+	if _, ok := DefaultGitRepositoryFactory().(*CLIRepositoryFactory); !ok {
+		t.Errorf("an invalid %s should fall back to the configured backend", BackendEnv)
+	}
+
+	if err := SetDefaultBackend("bogus"); err == nil {
+		t.Error("SetDefaultBackend() accepted an unknown backend")
+	}
+	t.Setenv("AIRULER_USE_MOCK_GIT", "1")
+	if _, ok := DefaultGitRepositoryFactory().(*MockRepositoryFactory); !ok {
+		t.Error("the mock factory should take precedence")
+	}
+}
+
+// TestBackendParity runs the same operations against a remote with both backends and
+// compares the results
+func TestBackendParity(t *testing.T) {
+	source := CreateTestRepositoryWithRemote(t)
+	source.CreateTag("v1.0.0", false)
+	firstCommit := source.GetCurrentCommit()
+	source.AddCommit("second")
+	source.CreateTag("v1.1.0", true)
+	runGit(t, source.Path, "push", "--quiet", "--tags", "origin", "HEAD")
+	secondCommit := source.GetCurrentCommit()
+
+	type observation struct {
+		Cloned, Remote, Pulled string
+		HadUpdates             bool
+		Refs                   []string
+		CheckedOut, Restored   string
+	}
+	observe := func(t *testing.T, factory RepositoryFactory) observation {
+		t.Helper()
+		var obs observation
+		repo := factory.NewRepository(source.Remote, filepath.Join(t.TempDir(), "clone"))
+
+		if err := repo.Clone(); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+		if !repo.Exists() {
+			t.Fatal("Exists() = false after Clone()")
+		}
+
+		var err error
+		if obs.Cloned, err = repo.GetCurrentCommit(); err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+
+		refs, err := repo.ListRemoteRefs()
+		if err != nil {
+			t.Fatalf("ListRemoteRefs() error = %v", err)
+		}
+		for _, ref := range refs {
+			obs.Refs = append(obs.Refs, fmt.Sprint(ref.Kind)+" "+ref.Name+" "+ref.Commit)
+		}
+		sort.Strings(obs.Refs)
+
+		if err := repo.CheckoutCommit(firstCommit); err != nil {
+			t.Fatalf("CheckoutCommit() error = %v", err)
+		}
+		if obs.CheckedOut, err = repo.GetCurrentCommit(); err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+		if err := repo.CheckoutMainBranch(); err != nil {
+			t.Fatalf("CheckoutMainBranch() error = %v", err)
+		}
+		if obs.Restored, err = repo.GetCurrentCommit(); err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+
+		source.AddCommit("update-" + strings.ReplaceAll(t.Name(), "/", "-"))
+		source.PushToRemote()
+		if obs.HadUpdates, err = repo.HasUpdates(); err != nil {
+			t.Fatalf("HasUpdates() error = %v", err)
+		}
+		if obs.Remote, err = repo.GetRemoteCommit(); err != nil {
+			t.Fatalf("GetRemoteCommit() error = %v", err)
+		}
+		if err := repo.Pull(); err != nil {
+			t.Fatalf("Pull() error = %v", err)
+		}
+		if obs.Pulled, err = repo.GetCurrentCommit(); err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+
+		// Each backend pushes its own update; compare where it landed, not which commit it was
+		if obs.Remote != obs.Pulled || obs.Remote != source.GetCurrentCommit() {
+			t.Errorf("after update remote = %s, pulled = %s, want %s", obs.Remote, obs.Pulled, source.GetCurrentCommit())
+		}
+		obs.Remote, obs.Pulled = "", ""
+		return obs
+	}
+
+	goGit := observe(t, NewGoGitRepositoryFactory())
+	// Refs are listed before the go-git update was pushed, so the CLI backend sees the same ones
+	runGit(t, source.Path, "reset", "--quiet", "--hard", secondCommit)
+	runGit(t, source.Path, "push", "--quiet", "--force", "origin", "HEAD")
+	cli := observe(t, NewCLIRepositoryFactory())
+
+	if !reflect.DeepEqual(goGit, cli) {
+		t.Errorf("backends disagree:\ngo-git: %+v\ngit:    %+v", goGit, cli)
+	}
+	if goGit.Cloned != secondCommit || goGit.CheckedOut != firstCommit || !goGit.HadUpdates {
+		t.Errorf("go-git observations = %+v", goGit)
+	}
+}
+
+func TestCLIRepository_Errors(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("git is not available, skipping git backend tests")
+	}
+
+	missing := NewCLIRepositoryFactory().NewRepository("https://example.com/repo.git", filepath.Join(t.TempDir(), "missing"))
+	if _, err := missing.GetCurrentCommit(); err == nil || !strings.Contains(err.Error(), "repository does not exist") {
+		t.Errorf("GetCurrentCommit() error = %v", err)
+	}
+	if err := missing.Pull(); err == nil || !strings.Contains(err.Error(), "repository does not exist") {
+		t.Errorf("Pull() error = %v", err)
+	}
+
+	// Failed clones leave nothing behind and report git's own message
+	localPath := filepath.Join(t.TempDir(), "clone")
+	repo := NewCLIRepositoryFactory().NewRepository(filepath.Join(t.TempDir(), "nowhere"), localPath)
+	err := repo.Clone()
+	if err == nil || !strings.Contains(err.Error(), "failed to clone repository: git clone:") {
+		t.Errorf("Clone() error = %v", err)
+	}
+	if repo.Exists() {
+		t.Error("failed clone left a repository behind")
+	}
+}
+
+func TestCLIAuthEnv(t *testing.T) {
+	t.Setenv("AIRULER_TEST_TOKEN", "s3cret")
+
+	tests := []struct {
+		name        string
+		remote      string
+		cfg         AuthConfig
+		wantEnv     []string
+		wantDesc    string
+		wantErr     string
+		sshAuthSock string
+	}{
+		{name: "auto defers to git configuration", remote: "git@github.com:acme/rules.git"},
+		{name: "auto https defers to git configuration", remote: "https://github.com/acme/rules"},
+		{
+			name:     "auto token",
+			remote:   "https://github.com/acme/rules",
+			cfg:      AuthConfig{TokenEnv: "AIRULER_TEST_TOKEN"},
+			wantEnv:  configEnv("http.extraHeader", "Authorization: Basic "+base64.StdEncoding.EncodeToString([]byte("git:s3cret"))),
+			wantDesc: "token from $AIRULER_TEST_TOKEN",
+		},
+		{
+			name:     "ssh key",
+			remote:   "git@github.com:acme/rules.git",
+			cfg:      AuthConfig{SSHKey: "/keys/id_deploy", Username: "deploy"},
+			wantEnv:  []string{"GIT_SSH_COMMAND=ssh -o IdentitiesOnly=yes -i '/keys/id_deploy' -l 'deploy'"},
+			wantDesc: "SSH key /keys/id_deploy",
+		},
+		{
+			name:    "ssh key passphrase",
+			remote:  "git@github.com:acme/rules.git",
+			cfg:     AuthConfig{Method: AuthSSHKey, SSHKey: "/keys/id_deploy", PassphraseEnv: "KEY_PASS"},
+			wantErr: "add the key to ssh-agent",
+		},
+		{
+			name:     "none disables credential helpers",
+			remote:   "https://github.com/acme/rules",
+			cfg:      AuthConfig{Method: AuthNone},
+			wantEnv:  configEnv("credential.helper", ""),
+			wantDesc: "anonymous access",
+		},
+		{
+			name:    "ssh agent without socket",
+			remote:  "git@github.com:acme/rules.git",
+			cfg:     AuthConfig{Method: AuthSSHAgent},
+			wantErr: "SSH_AUTH_SOCK is not set",
+		},
+		{
+			name:     "credential helper",
+			remote:   "https://github.com/acme/rules",
+			cfg:      AuthConfig{Method: AuthCredentialHelper},
+			wantDesc: "git credential helper",
+		},
+		{
+			name:    "missing token",
+			remote:  "https://github.com/acme/rules",
+			cfg:     AuthConfig{Method: AuthToken, TokenEnv: "AIRULER_TEST_MISSING"},
+			wantErr: "environment variable is not set",
+		},
+		{
+			name:    "unknown method",
+			remote:  "https://github.com/acme/rules",
+			cfg:     AuthConfig{Method: "kerberos"},
+			wantErr: "unknown auth method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSH_AUTH_SOCK", tt.sshAuthSock)
+
+			env, description, err := cliAuthEnv(tt.remote, tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("cliAuthEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cliAuthEnv() error = %v", err)
+			}
+			if !reflect.DeepEqual(env, tt.wantEnv) || description != tt.wantDesc {
+				t.Errorf("cliAuthEnv() = %q, %q; want %q, %q", env, description, tt.wantEnv, tt.wantDesc)
+			}
+		})
+	}
+}
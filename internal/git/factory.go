@@ -4,9 +4,45 @@
 package git
 
 import (
+	"fmt"
 	"os"
 )
 
+// Git backends
+const (
+	BackendGoGit = "go-git" // pure Go implementation, no system dependencies
+	BackendCLI   = "git"    // the git command line tool, honouring the user's git configuration
+)
+
+// BackendEnv is the environment variable that selects the git backend, overriding configuration
+const BackendEnv = "AIRULER_GIT_BACKEND"
+
+// defaultBackend is the backend used when AIRULER_GIT_BACKEND is not set
+var defaultBackend = BackendGoGit
+
+// NewRepositoryFactory returns the factory for a git backend; an empty backend selects go-git
+func NewRepositoryFactory(backend string) (RepositoryFactory, error) {
+	switch backend {
+	case "", BackendGoGit:
+		return NewGoGitRepositoryFactory(), nil
+	case BackendCLI:
+		return NewCLIRepositoryFactory(), nil
+	}
+	return nil, fmt.Errorf("unknown git backend %q (use %s or %s)", backend, BackendGoGit, BackendCLI)
+}
+
+// SetDefaultBackend sets the backend used by DefaultGitRepositoryFactory, typically from configuration
+func SetDefaultBackend(backend string) error {
+	if _, err := NewRepositoryFactory(backend); err != nil {
+		return err
+	}
+	if backend == "" {
+		backend = BackendGoGit
+	}
+	defaultBackend = backend
+	return nil
+}
+
 // DefaultGitRepositoryFactory returns the appropriate git factory based on configuration
 func DefaultGitRepositoryFactory() RepositoryFactory {
 	// Check if we should use mock for testing (highest priority)
@@ -14,8 +50,18 @@ func DefaultGitRepositoryFactory() RepositoryFactory {
 		return NewMockGitRepositoryFactory()
 	}
 
-	// Use go-git as default (pure Go implementation, no system dependencies)
-	return NewGoGitRepositoryFactory()
+	// The environment overrides configuration; an invalid value was already reported at startup
+	if backend := os.Getenv(BackendEnv); backend != "" {
+		if factory, err := NewRepositoryFactory(backend); err == nil {
+			return factory
+		}
+	}
+
+	factory, err := NewRepositoryFactory(defaultBackend)
+	if err != nil {
+		return NewGoGitRepositoryFactory()
+	}
+	return factory
 }
 
 // NewGitRepository creates a new git repository using the default factory
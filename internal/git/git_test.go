@@ -6,6 +6,7 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -535,3 +536,220 @@ func TestRepository_ResetToCommit(t *testing.T) {
 		})
 	}
 }
+
+// TestRepository_ListRemoteRefs tests listing and checking out tags of a remote
+func TestRepository_ListRemoteRefs(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		source := CreateTestRepository(t)
+		source.CreateTag("v1.0.0", false)
+		firstCommit := source.GetCurrentCommit()
+		source.AddCommit("second")
+		source.CreateTag("v1.1.0", true)
+		secondCommit := source.GetCurrentCommit()
+
+		repo := factory.NewRepository(source.Path, filepath.Join(t.TempDir(), "clone"))
+		refs, err := repo.ListRemoteRefs()
+		if err != nil {
+			t.Fatalf("ListRemoteRefs() error = %v", err)
+		}
+
+		found := make(map[string]RemoteRef)
+		for _, ref := range refs {
+			found[ref.Name] = ref
+		}
+
+		if ref := found["v1.0.0"]; ref.Kind != RefTag || ref.Commit != firstCommit {
+			t.Errorf("v1.0.0 = %+v, want tag at %s", ref, firstCommit)
+		}
+		if ref := found["v1.1.0"]; ref.Kind != RefTag || ref.Commit != secondCommit {
+			t.Errorf("annotated v1.1.0 = %+v, want tag peeled to %s", ref, secondCommit)
+		}
+		if ref := found["HEAD"]; ref.Kind != RefHead || ref.Commit != secondCommit {
+			t.Errorf("HEAD = %+v, want %s", ref, secondCommit)
+		}
+
+		if err := repo.Clone(); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+		if err := repo.FetchRefs(); err != nil {
+			t.Fatalf("FetchRefs() error = %v", err)
+		}
+		if err := repo.ResetToCommit(firstCommit); err != nil {
+			t.Fatalf("ResetToCommit() error = %v", err)
+		}
+		current, err := repo.GetCurrentCommit()
+		if err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+		if current != firstCommit {
+			t.Errorf("current commit = %s, want %s", current, firstCommit)
+		}
+	})
+}
+
+// TestRepository_ShallowSparseClone tests depth-limited, sparse clones and unshallowing on demand
+func TestRepository_ShallowSparseClone(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		source := CreateTestRepository(t)
+		firstCommit := source.GetCurrentCommit()
+		if err := os.MkdirAll(filepath.Join(source.Path, "templates"), 0755); err != nil {
+			t.Fatalf("Failed to create templates directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(source.Path, "templates", "rule.tmpl"), []byte("rule"), 0600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+		source.AddCommit("second")
+		source.AddCommit("third")
+		headCommit := source.GetCurrentCommit()
+
+		localPath := filepath.Join(t.TempDir(), "clone")
+		repo := factory.NewRepositoryWithOptions("file://"+source.Path, localPath, RepositoryOptions{
+			Depth:       1,
+			SparsePaths: []string{"templates/"},
+		})
+		if err := repo.Clone(); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(localPath, "templates", "rule.tmpl")); err != nil {
+			t.Errorf("sparse path should be checked out: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(localPath, "README.md")); !os.IsNotExist(err) {
+			t.Errorf("files outside the sparse paths should not be checked out, stat error = %v", err)
+		}
+
+		shallow, err := os.ReadFile(filepath.Join(localPath, ".git", "shallow"))
+		if err != nil || !strings.Contains(string(shallow), headCommit) {
+			t.Errorf("clone should be shallow at %s, got %q (%v)", headCommit, shallow, err)
+		}
+
+		// The first commit is beyond the clone depth and must be fetched on demand
+		if err := repo.ResetToCommit(firstCommit); err != nil {
+			t.Fatalf("ResetToCommit() error = %v", err)
+		}
+		current, err := repo.GetCurrentCommit()
+		if err != nil || current != firstCommit {
+			t.Errorf("current commit = %s (%v), want %s", current, err, firstCommit)
+		}
+		if _, err := os.Stat(filepath.Join(localPath, "templates", "rule.tmpl")); !os.IsNotExist(err) {
+			t.Errorf("templates did not exist at the first commit, stat error = %v", err)
+		}
+
+		// Pulling a shallow, sparse clone moves to the new tip and keeps the sparse paths
+		source.AddCommit("fourth")
+		newHead := source.GetCurrentCommit()
+		if err := repo.Pull(); err != nil {
+			t.Fatalf("Pull() error = %v", err)
+		}
+		current, err = repo.GetCurrentCommit()
+		if err != nil || current != newHead {
+			t.Errorf("current commit after pull = %s (%v), want %s", current, err, newHead)
+		}
+		if _, err := os.Stat(filepath.Join(localPath, "templates", "rule.tmpl")); err != nil {
+			t.Errorf("sparse path should be checked out after pull: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(localPath, "file-fourth.txt")); !os.IsNotExist(err) {
+			t.Errorf("files outside the sparse paths should stay out after pull, stat error = %v", err)
+		}
+	})
+}
+
+func TestRepository_ExportTree(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		source := CreateTestRepository(t)
+		if err := os.MkdirAll(filepath.Join(source.Path, "packs", "go", "templates"), 0755); err != nil {
+			t.Fatalf("Failed to create pack directory: %v", err)
+		}
+		rulePath := filepath.Join(source.Path, "packs", "go", "templates", "rule.tmpl")
+		if err := os.WriteFile(rulePath, []byte("first"), 0600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+		source.AddCommit("second")
+		firstCommit := source.GetCurrentCommit()
+		if err := os.WriteFile(rulePath, []byte("second"), 0600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+		source.AddCommit("third")
+
+		localPath := filepath.Join(t.TempDir(), "clone")
+		repo := factory.NewRepositoryWithOptions("file://"+source.Path, localPath, RepositoryOptions{Depth: 1})
+		if err := repo.Clone(); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+
+		// The older commit is beyond the clone depth and is fetched on demand
+		dest := filepath.Join(t.TempDir(), "export")
+		if err := repo.ExportTree(firstCommit, "packs/go", dest); err != nil {
+			t.Fatalf("ExportTree() error = %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(dest, "templates", "rule.tmpl"))
+		if err != nil || string(content) != "first" {
+			t.Errorf("exported template = %q (%v), want the first version", content, err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "README.md")); !os.IsNotExist(err) {
+			t.Errorf("files outside the path should not be exported, stat error = %v", err)
+		}
+
+		// The working tree stays at the cloned commit
+		checkedOut, err := os.ReadFile(filepath.Join(localPath, "packs", "go", "templates", "rule.tmpl"))
+		if err != nil || string(checkedOut) != "second" {
+			t.Errorf("working tree template = %q (%v), want it unchanged", checkedOut, err)
+		}
+
+		if err := repo.ExportTree(firstCommit, "packs/missing", dest); err == nil {
+			t.Error("ExportTree() of a missing path succeeded")
+		}
+	})
+}
+
+func TestRepository_StatusAndDiffFiles(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		source := CreateTestRepository(t)
+		repo := factory.NewRepository("", source.Path)
+
+		if dirty, err := repo.IsDirty(); err != nil || dirty {
+			t.Fatalf("IsDirty() = %v, %v; want a clean repository", dirty, err)
+		}
+
+		firstCommit := source.GetCurrentCommit()
+		source.AddCommit("added")
+		if err := os.Remove(filepath.Join(source.Path, "file-added.txt")); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(source.Path, "README.md"), []byte("changed"), 0600); err != nil {
+			t.Fatalf("Failed to modify README: %v", err)
+		}
+		source.AddCommit("second")
+		secondCommit := source.GetCurrentCommit()
+
+		changes, err := repo.DiffFiles(firstCommit, secondCommit)
+		if err != nil {
+			t.Fatalf("DiffFiles() error = %v", err)
+		}
+		want := []FileChange{{Path: "README.md", Status: FileModified}, {Path: "file-second.txt", Status: FileAdded}}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("DiffFiles() = %+v, want %+v", changes, want)
+		}
+		if _, err := repo.DiffFiles(firstCommit, "0123456789012345678901234567890123456789"); err == nil {
+			t.Error("DiffFiles() with an unknown commit succeeded")
+		}
+
+		if err := os.WriteFile(filepath.Join(source.Path, "README.md"), []byte("edited"), 0600); err != nil {
+			t.Fatalf("Failed to modify README: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(source.Path, "notes.txt"), []byte("new"), 0600); err != nil {
+			t.Fatalf("Failed to write untracked file: %v", err)
+		}
+		status, err := repo.Status()
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		want = []FileChange{{Path: "README.md", Status: FileModified}, {Path: "notes.txt", Status: FileUntracked}}
+		if !reflect.DeepEqual(status, want) {
+			t.Errorf("Status() = %+v, want %+v", status, want)
+		}
+		if dirty, err := repo.IsDirty(); err != nil || !dirty {
+			t.Errorf("IsDirty() = %v, %v; want a dirty repository", dirty, err)
+		}
+	})
+}
@@ -7,16 +7,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
+// unshallowDepth is the depth git itself uses to fetch the complete history of a shallow clone
+const unshallowDepth = 2147483647
+
 // GoGitRepository implements Repository interface using go-git library
 type GoGitRepository struct {
 	URL       string
 	LocalPath string
+	Options   RepositoryOptions
 }
 
 // GoGitRepositoryFactory creates repositories using go-git library
@@ -35,6 +45,15 @@ func (f *GoGitRepositoryFactory) NewRepository(url, localPath string) Repository
 	}
 }
 
+// NewRepositoryWithOptions creates a new repository instance using go-git with clone and checkout options
+func (f *GoGitRepositoryFactory) NewRepositoryWithOptions(url, localPath string, opts RepositoryOptions) Repository {
+	return &GoGitRepository{
+		URL:       url,
+		LocalPath: localPath,
+		Options:   opts,
+	}
+}
+
 // Clone clones the repository to the local path
 func (r *GoGitRepository) Clone() error {
 	// Ensure parent directory exists
@@ -43,12 +62,44 @@ func (r *GoGitRepository) Clone() error {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	// Clone repository using go-git
-	_, err := gogit.PlainClone(r.LocalPath, false, &gogit.CloneOptions{
-		URL: r.URL,
+	// Clone repository using go-git; sparse clones are checked out separately below
+	var repo *gogit.Repository
+	err := r.withAuth("clone repository", func(auth transport.AuthMethod) error {
+		var cloneErr error
+		repo, cloneErr = gogit.PlainClone(r.LocalPath, false, &gogit.CloneOptions{
+			URL:          r.URL,
+			Auth:         auth,
+			Depth:        r.Options.Depth,
+			SingleBranch: r.Options.Depth > 0,
+			NoCheckout:   len(r.Options.SparsePaths) > 0,
+		})
+		if cloneErr != nil {
+			_ = os.RemoveAll(r.LocalPath)
+		}
+		return cloneErr
 	})
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+		return err
+	}
+
+	if len(r.Options.SparsePaths) == 0 {
+		return nil
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := worktree.Checkout(&gogit.CheckoutOptions{
+		Branch:                    head.Name(),
+		Force:                     true,
+		SparseCheckoutDirectories: r.Options.SparsePaths,
+	}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", strings.Join(r.Options.SparsePaths, ", "), err)
 	}
 
 	return nil
@@ -66,6 +117,15 @@ func (r *GoGitRepository) Pull() error {
 		return fmt.Errorf("failed to open repository: %w", err)
 	}
 
+	// Shallow and sparse clones fetch the new tip and reset to it, keeping depth and sparse paths
+	if r.Options.Depth > 0 || len(r.Options.SparsePaths) > 0 {
+		remote, err := r.GetRemoteCommit()
+		if err != nil {
+			return err
+		}
+		return r.ResetToCommit(remote)
+	}
+
 	// Get working tree
 	worktree, err := repo.Worktree()
 	if err != nil {
@@ -73,12 +133,13 @@ func (r *GoGitRepository) Pull() error {
 	}
 
 	// Pull changes
-	err = worktree.Pull(&gogit.PullOptions{})
-	if err != nil && err != gogit.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to pull repository: %w", err)
-	}
-
-	return nil
+	return r.withAuth("pull repository", func(auth transport.AuthMethod) error {
+		err := worktree.Pull(&gogit.PullOptions{Auth: auth})
+		if err == gogit.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return err
+	})
 }
 
 // GetCurrentCommit returns the current commit hash
@@ -115,9 +176,15 @@ func (r *GoGitRepository) GetRemoteCommit() (string, error) {
 	}
 
 	// Fetch latest from remote
-	err = repo.Fetch(&gogit.FetchOptions{})
-	if err != nil && err != gogit.NoErrAlreadyUpToDate {
-		return "", fmt.Errorf("failed to fetch from remote: %w", err)
+	err = r.withAuth("fetch from remote", func(auth transport.AuthMethod) error {
+		err := repo.Fetch(&gogit.FetchOptions{Auth: auth, Depth: r.Options.Depth})
+		if err == gogit.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
 	}
 
 	// Try to get remote HEAD, with fallbacks to main/master
@@ -181,9 +248,14 @@ func (r *GoGitRepository) CheckoutCommit(commit string) error {
 	// Parse commit hash
 	hash := plumbing.NewHash(commit)
 
+	if err := r.ensureCommit(repo, hash); err != nil {
+		return err
+	}
+
 	// Checkout specific commit
 	err = worktree.Checkout(&gogit.CheckoutOptions{
-		Hash: hash,
+		Hash:                      hash,
+		SparseCheckoutDirectories: r.Options.SparsePaths,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to checkout commit %s: %w", commit, err)
@@ -215,7 +287,8 @@ func (r *GoGitRepository) CheckoutMainBranch() error {
 
 	for _, branch := range branches {
 		err = worktree.Checkout(&gogit.CheckoutOptions{
-			Branch: plumbing.NewBranchReferenceName(branch),
+			Branch:                    plumbing.NewBranchReferenceName(branch),
+			SparseCheckoutDirectories: r.Options.SparsePaths,
 		})
 		if err == nil {
 			return nil
@@ -246,11 +319,15 @@ func (r *GoGitRepository) ResetToCommit(commit string) error {
 	// Parse commit hash
 	hash := plumbing.NewHash(commit)
 
+	if err := r.ensureCommit(repo, hash); err != nil {
+		return err
+	}
+
 	// Reset to specific commit (hard reset)
-	err = worktree.Reset(&gogit.ResetOptions{
+	err = worktree.ResetSparsely(&gogit.ResetOptions{
 		Commit: hash,
 		Mode:   gogit.HardReset,
-	})
+	}, r.Options.SparsePaths)
 	if err != nil {
 		return fmt.Errorf("failed to reset to commit %s: %w", commit, err)
 	}
@@ -258,6 +335,301 @@ func (r *GoGitRepository) ResetToCommit(commit string) error {
 	return nil
 }
 
+// ListRemoteRefs lists the default branch, branches and tags advertised by the remote
+func (r *GoGitRepository) ListRemoteRefs() ([]RemoteRef, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{r.URL},
+	})
+
+	var refs []*plumbing.Reference
+	err := r.withAuth("list remote references", func(auth transport.AuthMethod) error {
+		var listErr error
+		refs, listErr = remote.List(&gogit.ListOptions{Auth: auth, PeelingOption: gogit.AppendPeeled})
+		return listErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[plumbing.ReferenceName]string)
+	peeled := make(map[plumbing.ReferenceName]string)
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		name := ref.Name()
+		switch {
+		case name == plumbing.HEAD:
+			head = ref
+		case strings.HasSuffix(name.String(), "^{}"):
+			peeled[plumbing.ReferenceName(strings.TrimSuffix(name.String(), "^{}"))] = ref.Hash().String()
+		case ref.Type() == plumbing.HashReference:
+			hashes[name] = ref.Hash().String()
+		}
+	}
+
+	var result []RemoteRef
+	if head != nil {
+		commit := head.Hash().String()
+		if head.Type() == plumbing.SymbolicReference {
+			commit = hashes[head.Target()]
+		}
+		if commit != "" {
+			result = append(result, RemoteRef{Name: "HEAD", Commit: commit, Kind: RefHead})
+		}
+	}
+
+	for name, commit := range hashes {
+		switch {
+		case name.IsBranch():
+			result = append(result, RemoteRef{Name: name.Short(), Commit: commit, Kind: RefBranch})
+		case name.IsTag():
+			// Annotated tags point at a tag object; use the commit it peels to
+			if target, ok := peeled[name]; ok {
+				commit = target
+			}
+			result = append(result, RemoteRef{Name: name.Short(), Commit: commit, Kind: RefTag})
+		}
+	}
+
+	return result, nil
+}
+
+// FetchRefs fetches all branches and tags from the remote into the local repository
+func (r *GoGitRepository) FetchRefs() error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	// Open repository
+	repo, err := gogit.PlainOpen(r.LocalPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	return r.fetchAll(repo, r.Options.Depth)
+}
+
+// ExportTree writes the files below path at a commit into dest, fetching the commit if needed
+func (r *GoGitRepository) ExportTree(commit, path, dest string) error {
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	repo, err := gogit.PlainOpen(r.LocalPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	hash := plumbing.NewHash(commit)
+	if err := r.ensureCommit(repo, hash); err != nil {
+		return err
+	}
+	commitObj, err := repo.CommitObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", commit, err)
+	}
+	tree, err := commitObj.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree of %s: %w", commit, err)
+	}
+	if path != "" {
+		if tree, err = tree.Tree(path); err != nil {
+			return fmt.Errorf("path %s not found at commit %s: %w", path, shortHash(commit), err)
+		}
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	return tree.Files().ForEach(func(file *object.File) error {
+		// Symlinks and submodules have no content of their own
+		if file.Mode != filemode.Regular && file.Mode != filemode.Executable {
+			return nil
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", file.Name, err)
+		}
+		if err := os.WriteFile(target, []byte(contents), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+		return nil
+	})
+}
+
+// Status lists the files with uncommitted changes, preferring the working tree state over the index
+func (r *GoGitRepository) Status() ([]FileChange, error) {
+	status, err := r.worktreeStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FileChange, 0, len(status))
+	for path, fileStatus := range status {
+		code := fileStatus.Worktree
+		if code == gogit.Unmodified {
+			code = fileStatus.Staging
+		}
+		if code == gogit.Unmodified {
+			continue
+		}
+		changes = append(changes, FileChange{Path: path, Status: fileStatusFromCode(code)})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// IsDirty reports whether the working tree or index has uncommitted changes
+func (r *GoGitRepository) IsDirty() (bool, error) {
+	status, err := r.worktreeStatus()
+	if err != nil {
+		return false, err
+	}
+	return !status.IsClean(), nil
+}
+
+// DiffFiles lists the files that differ between the trees of two commits
+func (r *GoGitRepository) DiffFiles(oldCommit, newCommit string) ([]FileChange, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	repo, err := gogit.PlainOpen(r.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	trees := make([]*object.Tree, 2)
+	for i, commit := range []string{oldCommit, newCommit} {
+		commitObj, err := repo.CommitObject(plumbing.NewHash(commit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", shortHash(commit), err)
+		}
+		if trees[i], err = commitObj.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get tree of %s: %w", shortHash(commit), err)
+		}
+	}
+
+	treeChanges, err := trees[0].Diff(trees[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s and %s: %w", shortHash(oldCommit), shortHash(newCommit), err)
+	}
+
+	changes := make([]FileChange, 0, len(treeChanges))
+	for _, treeChange := range treeChanges {
+		switch {
+		case treeChange.From.Name == "":
+			changes = append(changes, FileChange{Path: treeChange.To.Name, Status: FileAdded})
+		case treeChange.To.Name == "":
+			changes = append(changes, FileChange{Path: treeChange.From.Name, Status: FileDeleted})
+		default:
+			changes = append(changes, FileChange{Path: treeChange.To.Name, Status: FileModified})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// worktreeStatus returns the go-git status of the working tree
+func (r *GoGitRepository) worktreeStatus() (gogit.Status, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	repo, err := gogit.PlainOpen(r.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	return status, nil
+}
+
+// fileStatusFromCode converts a go-git status code to a FileStatus
+func fileStatusFromCode(code gogit.StatusCode) FileStatus {
+	switch code {
+	case gogit.Modified:
+		return FileModified
+	case gogit.Added:
+		return FileAdded
+	case gogit.Deleted:
+		return FileDeleted
+	case gogit.Renamed:
+		return FileRenamed
+	case gogit.Copied:
+		return FileCopied
+	case gogit.UpdatedButUnmerged:
+		return FileUnmerged
+	default:
+		return FileUntracked
+	}
+}
+
+// fetchAll fetches every branch and tag, limited to depth commits from each tip (0 for full history)
+func (r *GoGitRepository) fetchAll(repo *gogit.Repository, depth int) error {
+	return r.withAuth("fetch from remote", func(auth transport.AuthMethod) error {
+		err := repo.Fetch(&gogit.FetchOptions{
+			RefSpecs: []gitconfig.RefSpec{
+				"+refs/heads/*:refs/remotes/origin/*",
+				"+refs/tags/*:refs/tags/*",
+			},
+			Auth:  auth,
+			Tags:  gogit.AllTags,
+			Depth: depth,
+		})
+		if err == gogit.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return err
+	})
+}
+
+// ensureCommit makes sure a commit is available locally. Shallow clones first fetch the tips
+// of all branches and tags, and only fetch the full history when the commit is still missing.
+func (r *GoGitRepository) ensureCommit(repo *gogit.Repository, hash plumbing.Hash) error {
+	if _, err := repo.CommitObject(hash); err == nil {
+		return nil
+	}
+
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return fmt.Errorf("failed to read shallow state: %w", err)
+	}
+
+	if len(shallow) > 0 {
+		if err := r.fetchAll(repo, r.Options.Depth); err != nil {
+			return err
+		}
+		if _, err := repo.CommitObject(hash); err == nil {
+			return nil
+		}
+	}
+
+	depth := 0
+	if len(shallow) > 0 {
+		depth = unshallowDepth
+	}
+	if err := r.fetchAll(repo, depth); err != nil {
+		return err
+	}
+	if _, err := repo.CommitObject(hash); err != nil {
+		return fmt.Errorf("commit %s not found in %s", hash, r.URL)
+	}
+
+	return nil
+}
+
 // URLToDirectoryName converts a git URL to a directory name
 func URLToDirectoryName(url string) string {
 	// Convert git URL to directory name
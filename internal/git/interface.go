@@ -3,6 +3,56 @@
 
 package git
 
+// RefKind identifies what a remote reference points at
+type RefKind int
+
+const (
+	// RefHead is the remote's default branch (HEAD)
+	RefHead RefKind = iota
+	// RefBranch is a branch under refs/heads
+	RefBranch
+	// RefTag is a tag under refs/tags
+	RefTag
+	// RefCommit is a commit pinned directly by hash; remotes never advertise it
+	RefCommit
+)
+
+// RemoteRef is a branch or tag advertised by a remote repository
+type RemoteRef struct {
+	Name   string // short name, e.g. "main" or "v1.2.0"
+	Commit string // commit the reference points at (tags are peeled)
+	Kind   RefKind
+}
+
+// FileStatus is how a file changed, as the letter git status --short prints
+type FileStatus string
+
+const (
+	FileModified  FileStatus = "M"
+	FileAdded     FileStatus = "A"
+	FileDeleted   FileStatus = "D"
+	FileRenamed   FileStatus = "R"
+	FileCopied    FileStatus = "C"
+	FileUnmerged  FileStatus = "U"
+	FileUntracked FileStatus = "?"
+)
+
+// FileChange is a file that differs between two commits, or between HEAD and the working tree
+type FileChange struct {
+	Path   string // slash-separated path relative to the repository root
+	Status FileStatus
+}
+
+// RepositoryOptions tunes how a repository is cloned, fetched and checked out
+type RepositoryOptions struct {
+	// Depth limits clones and fetches to this many commits from each tip; 0 fetches full history
+	Depth int
+	// SparsePaths limits the working tree to these path prefixes, relative to the repository root
+	SparsePaths []string
+	// Auth selects the credentials used for clones, fetches and remote listings
+	Auth AuthConfig
+}
+
 // Repository defines the interface for git repository operations
 type Repository interface {
 	// Clone clones the repository to the local path
@@ -34,10 +84,36 @@ type Repository interface {
 
 	// ResetToCommit resets the repository to a specific commit
 	ResetToCommit(commit string) error
+
+	// ListRemoteRefs lists the default branch, branches and tags advertised by the remote
+	ListRemoteRefs() ([]RemoteRef, error)
+
+	// FetchRefs fetches all branches and tags from the remote into the local repository
+	FetchRefs() error
+
+	// VerifySignature checks that a commit, or the signed tag pointing at it, is signed by a
+	// trusted key and returns a description of the signer
+	VerifySignature(commit, tag string, keys TrustedKeys) (string, error)
+
+	// ExportTree writes the files below path, relative to the repository root, at a commit into
+	// dest without touching the working tree; an empty path exports the whole tree
+	ExportTree(commit, path, dest string) error
+
+	// Status lists the files with uncommitted changes, including untracked files, sorted by path
+	Status() ([]FileChange, error)
+
+	// IsDirty reports whether the working tree or index has uncommitted changes
+	IsDirty() (bool, error)
+
+	// DiffFiles lists the files that differ between two commits, sorted by path
+	DiffFiles(oldCommit, newCommit string) ([]FileChange, error)
 }
 
 // RepositoryFactory creates git repository instances
 type RepositoryFactory interface {
 	// NewRepository creates a new git repository instance
 	NewRepository(url, localPath string) Repository
+
+	// NewRepositoryWithOptions creates a new git repository instance with clone and checkout options
+	NewRepositoryWithOptions(url, localPath string, opts RepositoryOptions) Repository
 }
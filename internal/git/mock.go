@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // MockRepository implements Repository interface for testing
@@ -26,6 +27,16 @@ type MockRepository struct {
 	RemoveCalled         bool
 	CheckoutCommitCalled bool
 	ResetCalled          bool
+	FetchRefsCalled      bool
+	MockRemoteRefs       []RemoteRef
+	Options              RepositoryOptions
+	MockSigner           string // signer reported by VerifySignature; empty means unsigned
+	VerifiedCommits      []string
+	MockTrees            map[string]map[string]string // files by path at each commit, for ExportTree
+	ExportedCommits      []string
+	MockPulledCommit     string       // commit Pull moves to; empty leaves the current commit
+	MockStatus           []FileChange // uncommitted changes reported by Status and IsDirty
+	MockChangedFiles     []FileChange // files reported by DiffFiles
 }
 
 // MockRepositoryFactory creates mock repositories for testing
@@ -58,6 +69,13 @@ func (f *MockRepositoryFactory) NewRepository(url, localPath string) Repository
 	return repo
 }
 
+// NewRepositoryWithOptions creates a new mock repository instance and records the options used
+func (f *MockRepositoryFactory) NewRepositoryWithOptions(url, localPath string, opts RepositoryOptions) Repository {
+	repo := f.NewRepository(url, localPath).(*MockRepository)
+	repo.Options = opts
+	return repo
+}
+
 // ConfigureRepository allows test setup of mock behavior
 func (f *MockRepositoryFactory) ConfigureRepository(url, localPath string, config func(*MockRepository)) {
 	key := fmt.Sprintf("%s:%s", url, localPath)
@@ -95,6 +113,9 @@ func (r *MockRepository) Pull() error {
 	if !r.Exists() {
 		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
 	}
+	if r.MockPulledCommit != "" {
+		r.MockCurrentCommit = r.MockPulledCommit
+	}
 	return nil
 }
 
@@ -171,14 +192,104 @@ func (r *MockRepository) CheckoutMainBranch() error {
 }
 
 // ResetToCommit implementation for mock
-func (r *MockRepository) ResetToCommit(_ string) error {
+func (r *MockRepository) ResetToCommit(commit string) error {
 	r.ResetCalled = true
 	if !r.Exists() {
 		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
 	}
+	if commit != "" {
+		r.MockCurrentCommit = commit
+	}
 	return nil
 }
 
+// ListRemoteRefs implementation for mock
+func (r *MockRepository) ListRemoteRefs() ([]RemoteRef, error) {
+	if r.ShouldFailCommits {
+		return nil, fmt.Errorf("mock remote refs listing failed")
+	}
+	if r.MockRemoteRefs != nil {
+		return r.MockRemoteRefs, nil
+	}
+	return []RemoteRef{{Name: "HEAD", Commit: r.MockRemoteCommit, Kind: RefHead}}, nil
+}
+
+// FetchRefs implementation for mock
+func (r *MockRepository) FetchRefs() error {
+	r.FetchRefsCalled = true
+	if r.ShouldFailPull {
+		return fmt.Errorf("mock fetch failed")
+	}
+	if !r.Exists() {
+		return fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	return nil
+}
+
+// VerifySignature implementation for mock
+func (r *MockRepository) VerifySignature(commit, _ string, _ TrustedKeys) (string, error) {
+	r.VerifiedCommits = append(r.VerifiedCommits, commit)
+	if !r.Exists() {
+		return "", fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	if r.MockSigner == "" {
+		return "", fmt.Errorf("commit %s is not signed", shortHash(commit))
+	}
+	return r.MockSigner, nil
+}
+
+// ExportTree implementation for mock
+func (r *MockRepository) ExportTree(commit, path, dest string) error {
+	r.ExportedCommits = append(r.ExportedCommits, commit)
+	files, exists := r.MockTrees[commit]
+	if !exists {
+		return fmt.Errorf("commit %s not found in %s", commit, r.URL)
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = strings.TrimSuffix(path, "/") + "/"
+	}
+	for name, content := range files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		target := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(dest, 0755)
+}
+
+// Status implementation for mock
+func (r *MockRepository) Status() ([]FileChange, error) {
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	return r.MockStatus, nil
+}
+
+// IsDirty implementation for mock
+func (r *MockRepository) IsDirty() (bool, error) {
+	status, err := r.Status()
+	return len(status) > 0, err
+}
+
+// DiffFiles implementation for mock
+func (r *MockRepository) DiffFiles(_, _ string) ([]FileChange, error) {
+	if r.ShouldFailCommits {
+		return nil, fmt.Errorf("mock diff failed")
+	}
+	if !r.Exists() {
+		return nil, fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+	return r.MockChangedFiles, nil
+}
+
 // Ensure MockRepository implements Repository interface
 var _ Repository = (*MockRepository)(nil)
 var _ RepositoryFactory = (*MockRepositoryFactory)(nil)
@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureFooter = "-----END SSH SIGNATURE-----"
+	pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+	pgpPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+	// sshSignatureNamespace is the namespace git uses for SSH signatures on commits and tags
+	sshSignatureNamespace = "git"
+)
+
+// TrustedKeys are the public keys allowed to sign a repository's tags and commits
+type TrustedKeys struct {
+	SSH []ssh.PublicKey
+	GPG openpgp.EntityList
+}
+
+// Empty reports whether no keys are trusted
+func (k TrustedKeys) Empty() bool {
+	return len(k.SSH) == 0 && len(k.GPG) == 0
+}
+
+// ParseTrustedKeys parses signer entries into trusted keys. An entry is an SSH public key
+// ("ssh-ed25519 AAAA..."), an armored GPG public key, or the path to a file holding either:
+// an authorized_keys or allowed_signers file, or an exported GPG key.
+func ParseTrustedKeys(entries []string) (TrustedKeys, error) {
+	var keys TrustedKeys
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		data := entry
+		inline := strings.HasPrefix(entry, pgpPublicKeyHeader) || parseSSHKeyLine(entry) != nil
+		if !inline {
+			content, err := os.ReadFile(expandHome(entry))
+			if err != nil {
+				return keys, fmt.Errorf("signer %q is not an SSH key, GPG key or readable key file: %w", entry, err)
+			}
+			data = string(content)
+		}
+
+		if strings.Contains(data, pgpPublicKeyHeader) {
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(data))
+			if err != nil {
+				return keys, fmt.Errorf("failed to read GPG key %s: %w", describeEntry(entry, inline), err)
+			}
+			keys.GPG = append(keys.GPG, entities...)
+			continue
+		}
+
+		found := 0
+		for _, line := range strings.Split(data, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key := parseSSHKeyLine(line)
+			if key == nil {
+				return keys, fmt.Errorf("invalid SSH key in %s: %q", describeEntry(entry, inline), line)
+			}
+			keys.SSH = append(keys.SSH, key)
+			found++
+		}
+		if found == 0 {
+			return keys, fmt.Errorf("no keys found in %s", describeEntry(entry, inline))
+		}
+	}
+	return keys, nil
+}
+
+// parseSSHKeyLine parses an authorized_keys or allowed_signers line, skipping any
+// principals and options in front of the key; it returns nil when the line holds no key
+func parseSSHKeyLine(line string) ssh.PublicKey {
+	fields := strings.Fields(line)
+	for i := range fields {
+		if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields[i:], " "))); err == nil {
+			return key
+		}
+	}
+	return nil
+}
+
+func describeEntry(entry string, inline bool) string {
+	if inline {
+		return "signer entry"
+	}
+	return entry
+}
+
+// VerifySignature checks that a commit, or the annotated tag pointing at it, carries a valid
+// signature from one of the trusted keys and returns a description of the signer. A signed
+// tag is verified in preference to the commit; a lightweight or unsigned tag falls back to
+// the commit's own signature.
+func (r *GoGitRepository) VerifySignature(commit, tag string, keys TrustedKeys) (string, error) {
+	if !r.Exists() {
+		return "", fmt.Errorf("repository does not exist at %s", r.LocalPath)
+	}
+
+	repo, err := gogit.PlainOpen(r.LocalPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if tag != "" {
+		signer, signed, err := r.verifyTag(repo, commit, tag, keys)
+		if signed || err != nil {
+			return signer, err
+		}
+	}
+
+	hash := plumbing.NewHash(commit)
+	if err := r.ensureCommit(repo, hash); err != nil {
+		return "", err
+	}
+	commitObject, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	if commitObject.PGPSignature == "" {
+		if tag != "" {
+			return "", fmt.Errorf("neither tag %s nor commit %s is signed", tag, shortHash(commit))
+		}
+		return "", fmt.Errorf("commit %s is not signed", shortHash(commit))
+	}
+
+	payload := &plumbing.MemoryObject{}
+	if err := commitObject.EncodeWithoutSignature(payload); err != nil {
+		return "", fmt.Errorf("failed to encode commit %s: %w", commit, err)
+	}
+	return verifyPayload("commit "+shortHash(commit), commitObject.PGPSignature, payload, keys)
+}
+
+// verifyTag verifies a signed annotated tag; signed is false for lightweight and unsigned tags
+func (r *GoGitRepository) verifyTag(repo *gogit.Repository, commit, tag string, keys TrustedKeys) (string, bool, error) {
+	ref, err := repo.Tag(tag)
+	if err == gogit.ErrTagNotFound {
+		if err := r.fetchAll(repo, r.Options.Depth); err != nil {
+			return "", false, err
+		}
+		ref, err = repo.Tag(tag)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to find tag %s: %w", tag, err)
+	}
+
+	tagObject, err := repo.TagObject(ref.Hash())
+	if err == plumbing.ErrObjectNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read tag %s: %w", tag, err)
+	}
+	if tagObject.PGPSignature == "" {
+		return "", false, nil
+	}
+
+	target, err := tagObject.Commit()
+	if err != nil {
+		return "", true, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	if target.Hash.String() != commit {
+		return "", true, fmt.Errorf("tag %s points at %s, not %s", tag, shortHash(target.Hash.String()), shortHash(commit))
+	}
+
+	payload := &plumbing.MemoryObject{}
+	if err := tagObject.EncodeWithoutSignature(payload); err != nil {
+		return "", true, fmt.Errorf("failed to encode tag %s: %w", tag, err)
+	}
+	signer, err := verifyPayload("tag "+tag, tagObject.PGPSignature, payload, keys)
+	return signer, true, err
+}
+
+// verifyPayload checks an armored SSH or GPG signature over an encoded object
+func verifyPayload(subject, signature string, payload *plumbing.MemoryObject, keys TrustedKeys) (string, error) {
+	reader, err := payload.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case strings.HasPrefix(signature, sshSignatureHeader):
+		key, err := verifySSHSignature(signature, data, keys.SSH)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", subject, err)
+		}
+		return "SSH key " + ssh.FingerprintSHA256(key), nil
+	case strings.HasPrefix(signature, pgpSignatureHeader):
+		if len(keys.GPG) == 0 {
+			return "", fmt.Errorf("%s has a GPG signature, but no GPG keys are trusted", subject)
+		}
+		entity, err := openpgp.CheckArmoredDetachedSignature(keys.GPG, bytes.NewReader(data), strings.NewReader(signature), nil)
+		if err != nil {
+			return "", fmt.Errorf("%s has no valid signature from a trusted GPG key: %w", subject, err)
+		}
+		return describeEntity(entity), nil
+	}
+	return "", fmt.Errorf("%s has an unsupported signature format", subject)
+}
+
+// sshSignature is the binary SSHSIG structure from OpenSSH's PROTOCOL.sshsig
+type sshSignature struct {
+	Magic         [6]byte
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// verifySSHSignature verifies an armored SSHSIG signature in the git namespace over data
+// and returns the signing key, which must be one of the trusted keys
+func verifySSHSignature(armored string, data []byte, trusted []ssh.PublicKey) (ssh.PublicKey, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, sshSignatureHeader)
+	if end := strings.Index(body, sshSignatureFooter); end >= 0 {
+		body = body[:end]
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SSH signature: %w", err)
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(raw, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse SSH signature: %w", err)
+	}
+	if string(sig.Magic[:]) != "SSHSIG" || sig.Version != 1 {
+		return nil, fmt.Errorf("unsupported SSH signature version")
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return nil, fmt.Errorf("SSH signature has namespace %q, not %q", sig.Namespace, sshSignatureNamespace)
+	}
+
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH signing key: %w", err)
+	}
+	if !isTrustedSSHKey(key, trusted) {
+		return nil, fmt.Errorf("signed by untrusted SSH key %s", ssh.FingerprintSHA256(key))
+	}
+
+	var digest []byte
+	switch sig.HashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(data)
+		digest = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(data)
+		digest = sum[:]
+	default:
+		return nil, fmt.Errorf("unsupported SSH signature hash %q", sig.HashAlgorithm)
+	}
+
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Digest        []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, digest})...)
+
+	var blob ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &blob); err != nil {
+		return nil, fmt.Errorf("failed to parse SSH signature blob: %w", err)
+	}
+	if err := key.Verify(signed, &blob); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature from %s: %w", ssh.FingerprintSHA256(key), err)
+	}
+	return key, nil
+}
+
+func isTrustedSSHKey(key ssh.PublicKey, trusted []ssh.PublicKey) bool {
+	for _, candidate := range trusted {
+		if bytes.Equal(candidate.Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeEntity names a GPG key by its key ID and first user ID
+func describeEntity(entity *openpgp.Entity) string {
+	description := "GPG key " + entity.PrimaryKey.KeyIdString()
+	names := make([]string, 0, len(entity.Identities))
+	for name := range entity.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		description += " (" + names[0] + ")"
+	}
+	return description
+}
+
+// shortHash abbreviates a commit hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
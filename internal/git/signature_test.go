@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Stefan Wold <ratler@stderr.eu>
+
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeSSHKey generates an ed25519 key pair in dir and returns the private key path and
+// the public key in authorized_keys format
+func writeSSHKey(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(private, name)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyPath := filepath.Join(dir, name)
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return keyPath, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
+
+// runGit runs a git command in the test repository
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestParseTrustedKeys(t *testing.T) {
+	dir := t.TempDir()
+	_, publicKey := writeSSHKey(t, dir, "id_release")
+
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	content := "# release keys\nrelease@example.com namespaces=\"git\" " + publicKey + "\n"
+	if err := os.WriteFile(allowedSigners, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+
+	keys, err := ParseTrustedKeys([]string{publicKey, allowedSigners})
+	if err != nil {
+		t.Fatalf("ParseTrustedKeys() error = %v", err)
+	}
+	if len(keys.SSH) != 2 || len(keys.GPG) != 0 {
+		t.Errorf("ParseTrustedKeys() = %d SSH and %d GPG keys, want 2 and 0", len(keys.SSH), len(keys.GPG))
+	}
+
+	if _, err := ParseTrustedKeys([]string{filepath.Join(dir, "missing.pub")}); err == nil {
+		t.Error("ParseTrustedKeys() should fail for a missing key file")
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	if err := os.WriteFile(garbage, []byte("not a key\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ParseTrustedKeys([]string{garbage}); err == nil {
+		t.Error("ParseTrustedKeys() should fail for a file without keys")
+	}
+
+	if keys, err := ParseTrustedKeys(nil); err != nil || !keys.Empty() {
+		t.Errorf("ParseTrustedKeys(nil) = %+v, %v, want no keys", keys, err)
+	}
+}
+
+func TestVerifySignature_SSH(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		if _, err := exec.LookPath("ssh-keygen"); err != nil {
+			t.Skip("ssh-keygen is not available, skipping SSH signing test")
+		}
+
+		testRepo := CreateTestRepository(t)
+		keyDir := t.TempDir()
+		keyPath, publicKey := writeSSHKey(t, keyDir, "id_release")
+		_, otherKey := writeSSHKey(t, keyDir, "id_other")
+
+		runGit(t, testRepo.Path, "config", "gpg.format", "ssh")
+		runGit(t, testRepo.Path, "config", "user.signingkey", keyPath)
+
+		unsigned := testRepo.GetCurrentCommit()
+		runGit(t, testRepo.Path, "tag", "v0.9.0")
+		runGit(t, testRepo.Path, "commit", "--allow-empty", "-S", "-m", "Signed release")
+		signed := testRepo.GetCurrentCommit()
+		runGit(t, testRepo.Path, "tag", "-s", "v1.0.0", "-m", "Release v1.0.0")
+
+		trusted, err := ParseTrustedKeys([]string{publicKey})
+		if err != nil {
+			t.Fatalf("ParseTrustedKeys() error = %v", err)
+		}
+		untrusted, err := ParseTrustedKeys([]string{otherKey})
+		if err != nil {
+			t.Fatalf("ParseTrustedKeys() error = %v", err)
+		}
+
+		repo := factory.NewRepository("", testRepo.Path)
+
+		tests := []struct {
+			name    string
+			commit  string
+			tag     string
+			keys    TrustedKeys
+			wantErr string
+		}{
+			{name: "signed commit", commit: signed, keys: trusted},
+			{name: "signed tag", commit: signed, tag: "v1.0.0", keys: trusted},
+			{name: "untrusted key", commit: signed, keys: untrusted, wantErr: "untrusted SSH key"},
+			{name: "unsigned commit", commit: unsigned, keys: trusted, wantErr: "is not signed"},
+			{name: "lightweight tag on unsigned commit", commit: unsigned, tag: "v0.9.0", keys: trusted, wantErr: "neither tag v0.9.0"},
+			{name: "tag on another commit", commit: unsigned, tag: "v1.0.0", keys: trusted, wantErr: "points at"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				signer, err := repo.VerifySignature(tt.commit, tt.tag, tt.keys)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("VerifySignature() error = %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("VerifySignature() error = %v", err)
+				}
+				if !strings.HasPrefix(signer, "SSH key SHA256:") {
+					t.Errorf("VerifySignature() signer = %q", signer)
+				}
+			})
+		}
+	})
+}
+
+func TestVerifySignature_GPG(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, factory RepositoryFactory) {
+		testRepo := CreateTestRepository(t)
+
+		entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+		if err != nil {
+			t.Fatalf("Failed to generate GPG key: %v", err)
+		}
+		var armored bytes.Buffer
+		writer, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+		if err != nil {
+			t.Fatalf("Failed to armor key: %v", err)
+		}
+		if err := entity.Serialize(writer); err != nil {
+			t.Fatalf("Failed to serialize key: %v", err)
+		}
+		writer.Close()
+
+		repository, err := gogit.PlainOpen(testRepo.Path)
+		if err != nil {
+			t.Fatalf("Failed to open repository: %v", err)
+		}
+		worktree, err := repository.Worktree()
+		if err != nil {
+			t.Fatalf("Failed to get worktree: %v", err)
+		}
+		hash, err := worktree.Commit("Signed release", &gogit.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            &object.Signature{Name: "Release Bot", Email: "release@example.com", When: time.Now()},
+			SignKey:           entity,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create signed commit: %v", err)
+		}
+
+		trusted, err := ParseTrustedKeys([]string{armored.String()})
+		if err != nil {
+			t.Fatalf("ParseTrustedKeys() error = %v", err)
+		}
+
+		repo := factory.NewRepository("", testRepo.Path)
+		signer, err := repo.VerifySignature(hash.String(), "", trusted)
+		if err != nil {
+			t.Fatalf("VerifySignature() error = %v", err)
+		}
+		if !strings.Contains(signer, "release@example.com") {
+			t.Errorf("VerifySignature() signer = %q, want the key's identity", signer)
+		}
+
+		other, err := openpgp.NewEntity("Someone Else", "", "other@example.com", nil)
+		if err != nil {
+			t.Fatalf("Failed to generate GPG key: %v", err)
+		}
+		if _, err := repo.VerifySignature(hash.String(), "", TrustedKeys{GPG: openpgp.EntityList{other}}); err == nil {
+			t.Error("VerifySignature() should reject a signature from an untrusted GPG key")
+		}
+		if _, err := repo.VerifySignature(hash.String(), "", TrustedKeys{}); err == nil {
+			t.Error("VerifySignature() should reject a GPG signature when only SSH keys are trusted")
+		}
+	})
+}
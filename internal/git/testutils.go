@@ -250,6 +250,21 @@ func (tr *TestRepository) CheckoutBranch(branchName string) {
 	}
 }
 
+// CreateTag tags the current commit; annotated tags get a message
+func (tr *TestRepository) CreateTag(name string, annotated bool) {
+	tr.t.Helper()
+
+	args := []string{"tag", name}
+	if annotated {
+		args = []string{"tag", "-a", name, "-m", "Release " + name}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = tr.Path
+	if err := cmd.Run(); err != nil {
+		tr.t.Fatalf("Failed to create tag %s: %v", name, err)
+	}
+}
+
 // MockGitCommand represents a mock git command for testing without actual git
 type MockGitCommand struct {
 	Commands []MockCommand